@@ -15,6 +15,7 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message/router/middleware"
 	"github.com/ThreeDotsLabs/watermill/message/router/plugin"
 	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/fetch"
 	"github.com/rotationalio/baleen/logger"
 	"github.com/rotationalio/baleen/metrics"
 	"github.com/rs/zerolog"
@@ -42,6 +43,7 @@ type Baleen struct {
 	conf       config.Config
 	publisher  message.Publisher
 	subscriber message.Subscriber
+	fsync      *FeedSync
 }
 
 func New(conf config.Config) (svc *Baleen, err error) {
@@ -57,6 +59,20 @@ func New(conf config.Config) (svc *Baleen, err error) {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
 
+	// Configure the politeness of the fetchers (will modify fetching globally!)
+	fetch.SetRateLimit(fetch.RateLimit{
+		Rate:    conf.Fetch.Rate,
+		Burst:   conf.Fetch.Burst,
+		Domains: conf.Fetch.Domains,
+	})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{
+		MaxRetries:      conf.Retry.MaxRetries,
+		InitialInterval: conf.Retry.InitialInterval,
+		MaxInterval:     conf.Retry.MaxInterval,
+		Multiplier:      conf.Retry.Multiplier,
+		Jitter:          conf.Retry.Jitter,
+	})
+
 	svc = &Baleen{
 		conf: conf,
 	}
@@ -77,7 +93,8 @@ func New(conf config.Config) (svc *Baleen, err error) {
 
 		// The handler function is retried if it returns an error.
 		// After MaxRetries, the message is Nacked and it's up to the PubSub to resend it.
-		// TODO: configure max retries from environment
+		// Transient fetch failures are already retried with backoff by the fetch package
+		// (see config.RetryConfig) so handlers are not retried to avoid compounding them.
 		middleware.Retry{
 			MaxRetries:      0,
 			InitialInterval: time.Millisecond * 100,
@@ -89,11 +106,7 @@ func New(conf config.Config) (svc *Baleen, err error) {
 		middleware.Recoverer,
 	)
 
-	if svc.publisher, err = CreatePublisher(conf.Publisher, logger); err != nil {
-		return nil, err
-	}
-
-	if svc.subscriber, err = CreateSubscriber(conf.Subscriber, logger); err != nil {
+	if svc.publisher, svc.subscriber, err = CreatePubSub(conf.Publisher, conf.Subscriber, logger); err != nil {
 		return nil, err
 	}
 
@@ -110,6 +123,18 @@ func New(conf config.Config) (svc *Baleen, err error) {
 		}
 	}
 
+	if conf.AWS.Enabled {
+		if err = svc.AddS3Sink(conf.AWS); err != nil {
+			return nil, err
+		}
+	}
+
+	if conf.FileSink.Enabled {
+		if err = svc.AddFileSink(conf.FileSink); err != nil {
+			return nil, err
+		}
+	}
+
 	return svc, nil
 }
 
@@ -124,6 +149,23 @@ func (s *Baleen) Run(ctx context.Context) error {
 	return s.router.Run(ctx)
 }
 
+// Running returns a channel that is closed when the Baleen router is running.
+func (s *Baleen) Running() chan struct{} {
+	return s.router.Running()
+}
+
+// Publisher returns the publisher of the service, e.g. to publish subscriptions to an
+// in-process pub/sub. The publisher is closed when the service is closed.
+func (s *Baleen) Publisher() message.Publisher {
+	return s.publisher
+}
+
+// Subscriber returns the subscriber of the service, e.g. to listen to the events of an
+// in-process pub/sub. The subscriber is closed when the service is closed.
+func (s *Baleen) Subscriber() message.Subscriber {
+	return s.subscriber
+}
+
 func (s *Baleen) Close() error {
 	// Shutdown the metrics server if it was enabled
 	if s.conf.Monitoring.Enabled {
@@ -135,5 +177,24 @@ func (s *Baleen) Close() error {
 		}
 	}
 
-	return s.router.Close()
+	if err := s.router.Close(); err != nil {
+		return err
+	}
+
+	// The router only closes the publishers and subscribers of its handlers
+	if err := s.publisher.Close(); err != nil {
+		return err
+	}
+
+	if err := s.subscriber.Close(); err != nil {
+		return err
+	}
+
+	// Stop the feed sync routine and close the manifest once the handlers have stopped
+	if s.fsync != nil {
+		if err := s.fsync.Stop(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
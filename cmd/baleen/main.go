@@ -18,6 +18,7 @@ import (
 	"github.com/rotationalio/baleen/events"
 	"github.com/rotationalio/baleen/logger"
 	"github.com/rotationalio/baleen/opml"
+	"github.com/rotationalio/baleen/store"
 	"github.com/rotationalio/watermill-ensign/pkg/ensign"
 	"github.com/urfave/cli/v2"
 )
@@ -39,7 +40,23 @@ func main() {
 			Usage:  "run the baleen ingestion service",
 			Before: configure,
 			Action: run,
-			Flags:  []cli.Flag{},
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "url",
+					Aliases: []string{"u"},
+					Usage:   "add a subscription via its feed or site url once the service is running",
+				},
+				&cli.StringFlag{
+					Name:    "opml",
+					Aliases: []string{"o"},
+					Usage:   "add subscriptions from an OPML file once the service is running",
+				},
+				&cli.StringSliceFlag{
+					Name:    "debug",
+					Aliases: []string{"d"},
+					Usage:   "log the messages published to the specified topics in this process",
+				},
+			},
 		},
 		{
 			Name:   "feeds:add",
@@ -51,7 +68,7 @@ func main() {
 				&cli.StringFlag{
 					Name:    "url",
 					Aliases: []string{"u"},
-					Usage:   "add a new subscription via its xml url",
+					Usage:   "add a new subscription via its feed url or the url of its site",
 				},
 				&cli.StringFlag{
 					Name:    "opml",
@@ -60,6 +77,29 @@ func main() {
 				},
 			},
 		},
+		{
+			Name:   "feeds:export",
+			Usage:  "export the feed subscriptions in the manifest of a stopped node to an OPML file",
+			Before: configure,
+			Action: exportFeeds,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "out",
+					Aliases: []string{"o"},
+					Usage:   "write the subscriptions to an OPML file (json or xml) instead of stdout",
+				},
+				&cli.BoolFlag{
+					Name:    "json",
+					Aliases: []string{"j"},
+					Usage:   "write the subscriptions to stdout as json rather than xml",
+				},
+				&cli.StringFlag{
+					Name:    "manifest",
+					Aliases: []string{"m"},
+					Usage:   "path to the manifest database (defaults to the feed sync manifest path)",
+				},
+			},
+		},
 		{
 			Name:   "posts:add",
 			Usage:  "add posts for document processing",
@@ -123,6 +163,40 @@ func run(c *cli.Context) (err error) {
 	if svc, err = baleen.New(conf); err != nil {
 		return cli.Exit(err, 1)
 	}
+	defer svc.Close()
+
+	// Subscribe to the debug topics before the service starts so no messages are missed.
+	if topics := c.StringSlice("debug"); len(topics) > 0 {
+		// Tap into the topics in a group of their own so no messages are taken from the
+		// handlers; the in-process pub/sub only delivers to subscribers of the service.
+		subscriber := baleen.SubscriberGroup(svc.Subscriber(), baleen.GroupDebug)
+		defer subscriber.Close()
+
+		var msgs <-chan *message.Message
+		if msgs, err = multiplex(subscriber, topics); err != nil {
+			return cli.Exit(err, 1)
+		}
+		go logMessages(msgs)
+	}
+
+	// Publish any subscriptions specified on the command line with the publisher of the
+	// service once it is running, e.g. when using an in-process publisher and subscriber.
+	if c.String("url") != "" || c.String("opml") != "" {
+		var subs []*events.Subscription
+		if subs, err = subscriptions(c); err != nil {
+			return cli.Exit(err, 1)
+		}
+
+		publisher = svc.Publisher()
+		go func() {
+			<-svc.Running()
+			if err := publishSubscriptions(subs); err != nil {
+				log.Printf("could not publish subscriptions: %s", err)
+				return
+			}
+			log.Printf("published %d subscription events", len(subs))
+		}()
+	}
 
 	if err = svc.Run(context.Background()); err != nil {
 		return cli.Exit(err, 1)
@@ -131,56 +205,134 @@ func run(c *cli.Context) (err error) {
 }
 
 func addFeed(c *cli.Context) (err error) {
-	var nEvents int
 	if c.String("url") == "" && c.String("opml") == "" {
 		return cli.Exit("specify either -url or -opml to add a feed", 1)
 	}
 
+	var subs []*events.Subscription
+	if subs, err = subscriptions(c); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	if err = publishSubscriptions(subs); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	fmt.Printf("published %d subscription events\n", len(subs))
+	return nil
+}
+
+// Collects the subscriptions specified by the url and opml flags.
+func subscriptions(c *cli.Context) (subs []*events.Subscription, err error) {
 	// Handle single URL case
 	if url := c.String("url"); url != "" {
-		sub := &events.Subscription{
+		subs = append(subs, &events.Subscription{
 			FeedURL: url,
-		}
-
-		var msg *message.Message
-		if msg, err = events.Marshal(sub, watermill.NewULID()); err != nil {
-			return cli.Exit(err, 1)
-		}
-
-		if err = publisher.Publish(baleen.TopicSubscriptions, msg); err != nil {
-			return cli.Exit(err, 1)
-		}
-		nEvents++
+		})
 	}
 
 	// Handle OPML case
 	if path := c.String("opml"); path != "" {
 		var outline *opml.OPML
 		if outline, err = opml.Load(path); err != nil {
-			return cli.Exit(err, 1)
+			return nil, err
 		}
 
-		for _, feed := range outline.Body.Outlines {
+		// Feeds nested in folders are categorized by the names of the folders. If the
+		// outline only has a site url, the feed of the site is discovered by the feed sync.
+		for _, feed := range outline.Feeds() {
 			sub := &events.Subscription{
-				FeedType: feed.Type,
-				Title:    feed.Title,
-				FeedURL:  feed.XMLURL,
-				SiteURL:  feed.HTMLURL,
+				FeedType:   feed.Type,
+				Title:      feed.Title,
+				FeedURL:    feed.XMLURL,
+				SiteURL:    feed.HTMLURL,
+				Categories: feed.Categories,
 			}
 
-			var msg *message.Message
-			if msg, err = events.Marshal(sub, watermill.NewULID()); err != nil {
-				return cli.Exit(err, 1)
+			if sub.FeedURL == "" {
+				sub.FeedURL, sub.SiteURL = feed.HTMLURL, ""
 			}
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs, nil
+}
+
+func publishSubscriptions(subs []*events.Subscription) (err error) {
+	for _, sub := range subs {
+		var msg *message.Message
+		if msg, err = events.Marshal(sub, watermill.NewULID()); err != nil {
+			return err
+		}
 
-			if err = publisher.Publish(baleen.TopicSubscriptions, msg); err != nil {
-				return cli.Exit(err, 1)
+		if err = publisher.Publish(baleen.TopicSubscriptions, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Exports the subscriptions persisted in the feed sync manifest, including the status of
+// each feed. The manifest cannot be opened while the baleen service is running.
+// Export the feeds from the manifest on disk. The manifest is locked by the running
+// service, so only the manifest of a stopped node can be exported.
+func exportFeeds(c *cli.Context) (err error) {
+	path := c.String("manifest")
+	if path == "" {
+		path = conf.FeedSync.ManifestPath
+	}
+
+	var db *store.Manifest
+	if db, err = store.Open(path); err != nil {
+		return cli.Exit(fmt.Errorf("could not open manifest at %s (stop the baleen service before exporting its feeds): %w", path, err), 1)
+	}
+	defer db.Close()
+
+	var records []*store.Feed
+	if records, err = db.Feeds(); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	feeds := make([]opml.Feed, 0, len(records))
+	for _, record := range records {
+		feed := opml.Feed{
+			Title:      record.Title,
+			Type:       record.FeedType,
+			XMLURL:     record.URL,
+			HTMLURL:    record.SiteURL,
+			Categories: record.Categories,
+			Status:     "active",
+			Error:      record.Error,
+			Failures:   record.Failures,
+			SyncedAt:   record.SyncedAt,
+		}
+
+		if !record.Active {
+			feed.Status = "inactive"
+			if record.Reason != "" {
+				feed.Error = record.Reason
 			}
-			nEvents++
 		}
+		feeds = append(feeds, feed)
 	}
 
-	fmt.Printf("published %d subscription events\n", nEvents)
+	outline := opml.New("Baleen Subscriptions", feeds)
+	switch out := c.String("out"); {
+	case out != "":
+		if err = outline.Save(out); err != nil {
+			return cli.Exit(err, 1)
+		}
+		fmt.Printf("exported %d subscriptions to %s\n", len(feeds), out)
+	case c.Bool("json"):
+		err = outline.WriteJSON(os.Stdout)
+	default:
+		err = outline.WriteXML(os.Stdout)
+	}
+
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
 	return nil
 }
 
@@ -221,14 +373,27 @@ func debug(c *cli.Context) (err error) {
 	}
 	defer subscriber.Close()
 
-	// Create the message channel
-	msgs := make(chan *message.Message, 3)
+	// Tap into the topics in a group of their own so no messages are taken from the
+	// handlers of the running services.
+	tap := baleen.SubscriberGroup(subscriber, baleen.GroupDebug)
+	defer tap.Close()
 
-	// Create a multiplexing system for subscribing to topics
-	multiplex := func(topic string, msgs chan<- *message.Message) error {
-		var C <-chan *message.Message
-		if C, err = subscriber.Subscribe(context.Background(), topic); err != nil {
-			return cli.Exit(err, 1)
+	var msgs <-chan *message.Message
+	if msgs, err = multiplex(tap, c.StringSlice("topics")); err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	logMessages(msgs)
+	return nil
+}
+
+// Subscribe to all of the topics and multiplex their messages onto a single channel.
+func multiplex(subscriber message.Subscriber, topics []string) (<-chan *message.Message, error) {
+	msgs := make(chan *message.Message, 3)
+	for _, topic := range topics {
+		C, err := subscriber.Subscribe(context.Background(), topic)
+		if err != nil {
+			return nil, err
 		}
 
 		go func(in <-chan *message.Message, out chan<- *message.Message) {
@@ -236,15 +401,11 @@ func debug(c *cli.Context) (err error) {
 				out <- msg
 			}
 		}(C, msgs)
-		return nil
-	}
-
-	for _, topic := range c.StringSlice("topics") {
-		if err = multiplex(topic, msgs); err != nil {
-			return err
-		}
 	}
+	return msgs, nil
+}
 
+func logMessages(msgs <-chan *message.Message) {
 	for msg := range msgs {
 		etype := msg.Metadata.Get(ensign.TypeNameKey)
 		size := len(msg.Payload)
@@ -252,5 +413,4 @@ func debug(c *cli.Context) (err error) {
 
 		msg.Ack()
 	}
-	return nil
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"time"
 
@@ -28,19 +29,69 @@ type Config struct {
 	CloseTimeout time.Duration       `split_words:"true" default:"30s"`
 	FeedSync     FeedSyncConfig      `split_words:"true"`
 	PostFetch    PostFetchConfig     `split_words:"true"`
+	Fetch        FetchConfig
+	Retry        RetryConfig
+	AWS          AWSConfig
+	FileSink     FileSinkConfig `split_words:"true"`
 	Monitoring   MonitoringConfig
 	Publisher    PublisherConfig
 	Subscriber   SubscriberConfig
 	processed    bool
 }
 
+// FeedSyncConfig configures the feed sync handler. Each feed is polled on its own
+// schedule derived from the feed's hints and posting frequency, bounded by the min and
+// max intervals; the interval is used for feeds that provide no hints. The tick is how
+// often the feed sync checks for feeds that are due to be synchronized. Feeds that fail
+// max failures consecutive syncs (or that respond with 410 Gone) are deactivated and
+// polled on the inactive interval until they succeed; zero max failures disables this.
 type FeedSyncConfig struct {
-	Enabled  bool          `default:"false"`
-	Interval time.Duration `default:"1h"`
+	Enabled          bool          `default:"false"`
+	Interval         time.Duration `default:"1h"`
+	ManifestPath     string        `split_words:"true" default:"data/manifest"`
+	ItemRetention    time.Duration `split_words:"true" default:"720h"`
+	Workers          int           `default:"8"`
+	HostConcurrency  int           `split_words:"true" default:"2"`
+	MinInterval      time.Duration `split_words:"true" default:"15m"`
+	MaxInterval      time.Duration `split_words:"true" default:"24h"`
+	Tick             time.Duration `default:"1m"`
+	MaxFailures      int           `split_words:"true" default:"10"`
+	InactiveInterval time.Duration `split_words:"true" default:"168h"`
 }
 
+// PostFetchConfig configures the post fetch handler. By default the page linked to by
+// every feed item is fetched; if feed content is enabled, documents are created from
+// the content of the feed item instead when it contains the full text of the post, i.e.
+// at least min content length characters of text that do not appear to be truncated.
 type PostFetchConfig struct {
-	Enabled bool `default:"false"`
+	Enabled          bool `default:"false"`
+	FeedContent      bool `split_words:"true" default:"false"`
+	MinContentLength int  `split_words:"true" default:"1500"`
+}
+
+// FetchConfig configures the per-host rate limit of all requests made by the fetchers.
+// Requests to each host are limited to rate requests per second with bursts of up to
+// burst requests; a rate of zero disables the limit. The rate can be overridden for
+// specific domains, e.g. BALEEN_FETCH_DOMAINS=example.com:0.5,example.org:2. A host's
+// robots.txt Crawl-delay further limits the rate of requests to that host.
+type FetchConfig struct {
+	Rate    float64            `default:"1"`
+	Burst   int                `default:"2"`
+	Domains map[string]float64 `required:"false"`
+}
+
+// RetryConfig configures how requests that fail with a transient error (a network
+// error, a timeout, or a 429 or 5xx response) are retried by the fetchers. Requests
+// are retried up to max retries times with an exponential backoff that starts at the
+// initial interval, is multiplied by the multiplier after every retry and is capped at
+// the max interval; each interval is randomized by plus or minus the jitter fraction.
+// A Retry-After header longer than the interval is honored up to the max interval.
+type RetryConfig struct {
+	MaxRetries      int           `split_words:"true" default:"3"`
+	InitialInterval time.Duration `split_words:"true" default:"1s"`
+	MaxInterval     time.Duration `split_words:"true" default:"30s"`
+	Multiplier      float64       `default:"2"`
+	Jitter          float64       `default:"0.2"`
 }
 
 // MonitoringConfig maintains the parameters for the metrics server that the Prometheus
@@ -53,14 +104,16 @@ type MonitoringConfig struct {
 
 // Publisher Config defines the type of configuration to connect to the publisher with.
 type PublisherConfig struct {
-	Ensign EnsignConfig
-	Kafka  KafkaConfig
+	Ensign    EnsignConfig
+	Kafka     KafkaConfig
+	GoChannel GoChannelConfig
 }
 
 // Subscriber Config defines the type of configuration to connect to the publisher with.
 type SubscriberConfig struct {
-	Ensign EnsignConfig
-	Kafka  KafkaConfig
+	Ensign    EnsignConfig
+	Kafka     KafkaConfig
+	GoChannel GoChannelConfig
 }
 
 type EnsignConfig struct {
@@ -72,17 +125,54 @@ type EnsignConfig struct {
 }
 
 type KafkaConfig struct {
-	Enabled        bool   `default:"false"`
-	URL            string `split_words:"true"`
-	Balancer       string `default:"LeastBytes"`
-	TopicDocuments string `default:"documents"`
-	TopicFeeds     string `default:"feeds"`
+	Enabled            bool          `default:"false"`
+	URL                string        `split_words:"true"`
+	Balancer           string        `default:"LeastBytes"`
+	GroupID            string        `split_words:"true" default:"baleen"`
+	TopicSubscriptions string        `default:"subscriptions"`
+	TopicDocuments     string        `default:"documents"`
+	TopicFeeds         string        `default:"feeds"`
+	NackResendSleep    time.Duration `split_words:"true" default:"100ms"`
 }
 
+// Names of the Kafka balancers that can be specified in the KafkaConfig.
+var kafkaBalancers = map[string]struct{}{
+	"LeastBytes":    {},
+	"RoundRobin":    {},
+	"Hash":          {},
+	"ReferenceHash": {},
+	"CRC32":         {},
+	"Murmur2":       {},
+}
+
+// GoChannelConfig configures an in-process pub/sub that does not require a network
+// connection; this is primarily used for local development and testing. The publisher
+// and subscriber share a single in-process pub/sub, so both must be enabled to run a
+// pipeline in a single process. When enabled it takes precedence over Ensign and Kafka.
+type GoChannelConfig struct {
+	Enabled    bool  `default:"false"`
+	BufferSize int64 `split_words:"true" default:"64"`
+	Persistent bool  `default:"false"`
+}
+
+// AWSConfig configures the S3 document sink. The endpoint and path style options allow
+// documents to be written to an S3-compatible store (e.g. a local stand-in for testing)
+// rather than to AWS; credentials are loaded from the standard AWS environment.
 type AWSConfig struct {
+	Enabled   bool   `default:"false"`
+	Region    string `split_words:"true"`
+	Bucket    string `split_words:"true"`
+	Endpoint  string `split_words:"true"`
+	PathStyle bool   `split_words:"true" default:"false"`
+}
+
+// FileSinkConfig configures the local filesystem document sink, an alternative to S3
+// for development and air-gapped deployments. Documents are written to a directory
+// tree rooted at the path, optionally with a JSON sidecar of the document metadata.
+type FileSinkConfig struct {
 	Enabled bool   `default:"false"`
-	Region  string `split_words:"true"`
-	Bucket  string `split_words:"true"`
+	Path    string `default:"data/corpus"`
+	Sidecar bool   `default:"false"`
 }
 
 // New creates a new Config object, loading environment variables and defaults.
@@ -139,6 +229,26 @@ func (c Config) Validate() (err error) {
 		return err
 	}
 
+	if err = c.PostFetch.Validate(); err != nil {
+		return err
+	}
+
+	if err = c.Fetch.Validate(); err != nil {
+		return err
+	}
+
+	if err = c.Retry.Validate(); err != nil {
+		return err
+	}
+
+	if err = c.AWS.Validate(); err != nil {
+		return err
+	}
+
+	if err = c.FileSink.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -150,10 +260,14 @@ func (c Config) RouterConfig() message.RouterConfig {
 }
 
 func (c PublisherConfig) Validate() error {
-	if !c.Ensign.Enabled && !c.Kafka.Enabled {
+	if !c.Ensign.Enabled && !c.Kafka.Enabled && !c.GoChannel.Enabled {
 		return errors.New("invalid configuration: at least one publisher must be enabled")
 	}
 
+	if c.GoChannel.Enabled {
+		return c.GoChannel.Validate()
+	}
+
 	if c.Kafka.Enabled {
 		return c.Kafka.Validate()
 	}
@@ -162,10 +276,14 @@ func (c PublisherConfig) Validate() error {
 }
 
 func (c SubscriberConfig) Validate() error {
-	if !c.Ensign.Enabled && !c.Kafka.Enabled {
+	if !c.Ensign.Enabled && !c.Kafka.Enabled && !c.GoChannel.Enabled {
 		return errors.New("invalid configuration: at least one subscriber must be enabled")
 	}
 
+	if c.GoChannel.Enabled {
+		return c.GoChannel.Validate()
+	}
+
 	if c.Kafka.Enabled {
 		return c.Kafka.Validate()
 	}
@@ -195,6 +313,18 @@ func (c KafkaConfig) Validate() (err error) {
 			return errors.New("invalid configuration: kafka balancer must be specified")
 		}
 
+		if _, ok := kafkaBalancers[c.Balancer]; !ok {
+			return fmt.Errorf("invalid configuration: unknown kafka balancer %q", c.Balancer)
+		}
+
+		if c.GroupID == "" {
+			return errors.New("invalid configuration: kafka group id must be specified")
+		}
+
+		if c.TopicSubscriptions == "" {
+			return errors.New("invalid configuration: kafka topic for subscriptions must be specified")
+		}
+
 		if c.TopicDocuments == "" {
 			return errors.New("invalid configuration: kafka topic for documents must be specified")
 		}
@@ -202,8 +332,72 @@ func (c KafkaConfig) Validate() (err error) {
 		if c.TopicFeeds == "" {
 			return errors.New("invalid configuration: kafka topic for feeds must be specified")
 		}
+
+		if c.NackResendSleep < 0 {
+			return errors.New("invalid configuration: kafka nack resend sleep cannot be negative")
+		}
+	}
+
+	return nil
+}
+
+// Validate the GoChannel config.
+func (c GoChannelConfig) Validate() (err error) {
+	if c.Enabled && c.BufferSize < 0 {
+		return errors.New("invalid configuration: gochannel buffer size cannot be negative")
+	}
+	return nil
+}
+
+// Validate the post fetch config.
+func (c PostFetchConfig) Validate() (err error) {
+	if c.MinContentLength < 0 {
+		return errors.New("invalid configuration: min content length cannot be negative")
+	}
+	return nil
+}
+
+// Validate the fetch config.
+func (c FetchConfig) Validate() (err error) {
+	if c.Rate < 0 {
+		return errors.New("invalid configuration: fetch rate cannot be negative")
 	}
 
+	if c.Burst < 1 {
+		return errors.New("invalid configuration: fetch burst must be at least 1")
+	}
+
+	for domain, rate := range c.Domains {
+		if rate < 0 {
+			return fmt.Errorf("invalid configuration: fetch rate for %q cannot be negative", domain)
+		}
+	}
+	return nil
+}
+
+// Validate the retry config.
+func (c RetryConfig) Validate() (err error) {
+	if c.MaxRetries < 0 {
+		return errors.New("invalid configuration: max retries cannot be negative")
+	}
+
+	if c.MaxRetries > 0 {
+		if c.InitialInterval <= 0 {
+			return errors.New("invalid configuration: retry initial interval must be greater than zero")
+		}
+
+		if c.MaxInterval < c.InitialInterval {
+			return errors.New("invalid configuration: retry max interval must not be less than the initial interval")
+		}
+	}
+
+	if c.Multiplier < 1 {
+		return errors.New("invalid configuration: retry multiplier must be at least 1")
+	}
+
+	if c.Jitter < 0 || c.Jitter > 1 {
+		return errors.New("invalid configuration: retry jitter must be between 0 and 1")
+	}
 	return nil
 }
 
@@ -221,3 +415,11 @@ func (c AWSConfig) Validate() (err error) {
 
 	return nil
 }
+
+// Validate the FileSink config.
+func (c FileSinkConfig) Validate() (err error) {
+	if c.Enabled && c.Path == "" {
+		return errors.New("invalid configuration: file sink path must be specified")
+	}
+	return nil
+}
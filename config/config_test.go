@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/rotationalio/baleen/config"
 	"github.com/rs/zerolog"
@@ -17,6 +18,11 @@ var testEnv = map[string]string{
 	"BALEEN_MONITORING_ENABLED":        "true",
 	"BALEEN_MONITORING_BIND_ADDR":      ":8889",
 	"BALEEN_MONITORING_NODE_ID":        "test1234",
+	"BALEEN_FETCH_RATE":                "0.5",
+	"BALEEN_FETCH_DOMAINS":             "example.com:2,example.org:0",
+	"BALEEN_RETRY_MAX_RETRIES":         "5",
+	"BALEEN_RETRY_MAX_INTERVAL":        "1m",
+	"BALEEN_POST_FETCH_FEED_CONTENT":   "true",
 	"BALEEN_PUBLISHER_ENSIGN_ENABLED":  "true",
 	"BALEEN_SUBSCRIBER_ENSIGN_ENABLED": "true",
 }
@@ -36,6 +42,85 @@ func TestConfig(t *testing.T) {
 	require.True(t, conf.Monitoring.Enabled)
 	require.Equal(t, testEnv["BALEEN_MONITORING_BIND_ADDR"], conf.Monitoring.BindAddr)
 	require.Equal(t, testEnv["BALEEN_MONITORING_NODE_ID"], conf.Monitoring.NodeID)
+	require.Equal(t, 0.5, conf.Fetch.Rate)
+	require.Equal(t, 2, conf.Fetch.Burst)
+	require.Equal(t, map[string]float64{"example.com": 2, "example.org": 0}, conf.Fetch.Domains)
+	require.Equal(t, 5, conf.Retry.MaxRetries)
+	require.Equal(t, time.Second, conf.Retry.InitialInterval)
+	require.Equal(t, time.Minute, conf.Retry.MaxInterval)
+	require.Equal(t, 2.0, conf.Retry.Multiplier)
+	require.Equal(t, 0.2, conf.Retry.Jitter)
+}
+
+func TestFetchConfig(t *testing.T) {
+	conf := config.FetchConfig{Rate: 1, Burst: 2, Domains: map[string]float64{"example.com": 0.5}}
+	require.NoError(t, conf.Validate(), "expected valid fetch config")
+
+	conf.Rate = -1
+	require.EqualError(t, conf.Validate(), "invalid configuration: fetch rate cannot be negative")
+
+	conf.Rate = 0
+	conf.Burst = 0
+	require.EqualError(t, conf.Validate(), "invalid configuration: fetch burst must be at least 1")
+
+	conf.Burst = 1
+	conf.Domains["example.org"] = -0.5
+	require.EqualError(t, conf.Validate(), `invalid configuration: fetch rate for "example.org" cannot be negative`)
+}
+
+func TestKafkaConfig(t *testing.T) {
+	conf := config.KafkaConfig{
+		Enabled:            true,
+		URL:                "localhost:9092",
+		Balancer:           "LeastBytes",
+		GroupID:            "baleen",
+		TopicSubscriptions: "subscriptions",
+		TopicDocuments:     "documents",
+		TopicFeeds:         "feeds",
+	}
+	require.NoError(t, conf.Validate(), "expected valid kafka config")
+
+	conf.Balancer = "Random"
+	require.EqualError(t, conf.Validate(), `invalid configuration: unknown kafka balancer "Random"`)
+
+	conf.Balancer = "Murmur2"
+	conf.GroupID = ""
+	require.EqualError(t, conf.Validate(), "invalid configuration: kafka group id must be specified")
+
+	conf.GroupID = "baleen"
+	conf.TopicSubscriptions = ""
+	require.EqualError(t, conf.Validate(), "invalid configuration: kafka topic for subscriptions must be specified")
+
+	conf.TopicSubscriptions = "subscriptions"
+	conf.NackResendSleep = -time.Second
+	require.EqualError(t, conf.Validate(), "invalid configuration: kafka nack resend sleep cannot be negative")
+
+	// Disabled configs are not validated
+	conf.Enabled = false
+	require.NoError(t, conf.Validate())
+}
+
+func TestRetryConfig(t *testing.T) {
+	conf := config.RetryConfig{MaxRetries: 3, InitialInterval: time.Second, MaxInterval: 30 * time.Second, Multiplier: 2, Jitter: 0.2}
+	require.NoError(t, conf.Validate(), "expected valid retry config")
+
+	conf.MaxInterval = 500 * time.Millisecond
+	require.EqualError(t, conf.Validate(), "invalid configuration: retry max interval must not be less than the initial interval")
+
+	conf.MaxInterval = time.Minute
+	conf.Jitter = 1.5
+	require.EqualError(t, conf.Validate(), "invalid configuration: retry jitter must be between 0 and 1")
+
+	conf.Jitter = 0
+	conf.Multiplier = 0.5
+	require.EqualError(t, conf.Validate(), "invalid configuration: retry multiplier must be at least 1")
+
+	// Intervals are not validated if retries are disabled
+	conf = config.RetryConfig{MaxRetries: 0, Multiplier: 1}
+	require.NoError(t, conf.Validate())
+
+	conf.MaxRetries = -1
+	require.EqualError(t, conf.Validate(), "invalid configuration: max retries cannot be negative")
 }
 
 // Returns the current environment for the specified keys, or if no keys are specified
@@ -22,12 +22,18 @@ const (
 	TypeDocument     = "Document"
 )
 
+// The sources of the content of a Document.
+const (
+	SourceFeed = "feed"
+	SourcePage = "page"
+)
+
 // Versions specifies the semantic version for each event type
 const (
-	VersionSubscription = "1.0.0"
-	VersionFeedSync     = "1.0.0"
-	VersionFeedItem     = "1.0.0"
-	VersionDocument     = "1.0.0"
+	VersionSubscription = "1.2.0"
+	VersionFeedSync     = "1.3.0"
+	VersionFeedItem     = "1.2.0"
+	VersionDocument     = "1.6.0"
 )
 
 // Parsed ensign versions for each event type
@@ -51,6 +57,12 @@ type Subscription struct {
 	FeedType string `msg:"feed_type"`         // either rss or atom
 	FeedURL  string `msg:"feed_url"`          // the url to the feed (xmlURL in OPML)
 	SiteURL  string `msg:"site_url"`          // the url to the site (htmlURL in OPML)
+
+	// The categories of the feed, e.g. the names of the folders it was filed under in OPML
+	Categories []string `msg:"categories,omitempty"`
+
+	// If the feed has permanently moved, the url the feed was previously subscribed to
+	PreviousURL string `msg:"previous_url,omitempty"`
 }
 
 var _ TypedEvent = &Subscription{}
@@ -60,6 +72,11 @@ type FeedSync struct {
 	ETag         string    `msg:"etag"`
 	LastModified string    `msg:"last_modified"`
 	Active       bool      `msg:"active"`
+	NotModified  bool      `msg:"not_modified,omitempty"`
+	Disallowed   bool      `msg:"disallowed,omitempty"`
+	Deactivated  bool      `msg:"deactivated,omitempty"`
+	Reason       string    `msg:"reason,omitempty"`
+	Failures     int       `msg:"failures,omitempty"`
 	StatusCode   int       `msg:"status_code"`
 	Error        string    `msg:"error"`
 	SyncedAt     time.Time `msg:"synced_at"`
@@ -82,18 +99,21 @@ type FeedSync struct {
 var _ TypedEvent = &FeedSync{}
 
 type FeedItem struct {
-	FeedID      string   `msg:"feed_id"`
-	Title       string   `msg:"title"`
-	Description string   `msg:"description"`
-	Content     string   `msg:"content"`
-	Link        string   `msg:"link"`
-	Updated     string   `msg:"updated"`
-	Published   string   `msg:"published"`
-	GUID        string   `msg:"guid"`
-	Authors     []string `msg:"authors"`
-	Image       string   `msg:"image"`
-	Categories  []string `msg:"categories"`
-	Enclosures  []string `msg:"enclosures"`
+	FeedID      string    `msg:"feed_id"`
+	Title       string    `msg:"title"`
+	Description string    `msg:"description"`
+	Content     string    `msg:"content"`
+	ContentText string    `msg:"content_text,omitempty"`
+	Link        string    `msg:"link"`
+	Updated     string    `msg:"updated"`
+	Published   string    `msg:"published"`
+	GUID        string    `msg:"guid"`
+	Authors     []string  `msg:"authors"`
+	Image       string    `msg:"image"`
+	Categories  []string  `msg:"categories"`
+	Enclosures  []string  `msg:"enclosures"`
+	Language    string    `msg:"language,omitempty"`
+	PublishedAt time.Time `msg:"published_at"`
 }
 
 var _ TypedEvent = &FeedItem{}
@@ -104,6 +124,7 @@ type Document struct {
 	Active       bool      `msg:"active"`
 	StatusCode   int       `msg:"status_code,omitempty"`
 	Error        string    `msg:"error,omitempty"`
+	Disallowed   bool      `msg:"disallowed,omitempty"`
 	FetchedAt    time.Time `msg:"fetched_at"`
 	FeedID       string    `msg:"feed_id"`
 	Language     string    `msg:"language"`
@@ -114,7 +135,35 @@ type Document struct {
 	Description  string    `msg:"description"`
 	Content      []byte    `msg:"content"`
 	Encoding     string    `msg:"encoding"`
+	Charset      string    `msg:"charset,omitempty"`
 	Link         string    `msg:"link"`
+
+	// Whether the content of the document was taken from the feed item or fetched from
+	// the page it links to; either SourceFeed or SourcePage.
+	Source string `msg:"source,omitempty"`
+
+	// The main content of the document with the boilerplate removed as plain text and
+	// as a cleaned HTML fragment; the raw HTML of the document is in Content.
+	Text    string `msg:"text,omitempty"`
+	Article string `msg:"article,omitempty"`
+
+	// The language identified from the text of the document and the confidence of the
+	// identification between 0 and 1; may differ from the declared language.
+	DetectedLanguage   string  `msg:"detected_language,omitempty"`
+	LanguageConfidence float64 `msg:"language_confidence,omitempty"`
+
+	// Metadata extracted from the head of the document, including its OpenGraph and
+	// Twitter card properties keyed by property name and the JSON of the schema.org
+	// Article described by its JSON-LD.
+	Canonical   string            `msg:"canonical,omitempty"`
+	SiteName    string            `msg:"site_name,omitempty"`
+	Author      string            `msg:"author,omitempty"`
+	Keywords    []string          `msg:"keywords,omitempty"`
+	PublishedAt time.Time         `msg:"published_at,omitempty"`
+	ModifiedAt  time.Time         `msg:"modified_at,omitempty"`
+	OpenGraph   map[string]string `msg:"open_graph,omitempty"`
+	TwitterCard map[string]string `msg:"twitter_card,omitempty"`
+	LinkedData  []byte            `msg:"linked_data,omitempty"`
 }
 
 var _ TypedEvent = &Document{}
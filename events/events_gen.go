@@ -3,6 +3,8 @@ package events
 // Code generated by github.com/tinylib/msgp DO NOT EDIT.
 
 import (
+	"time"
+
 	"github.com/tinylib/msgp/msgp"
 )
 
@@ -54,6 +56,12 @@ func (z *Document) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Error")
 				return
 			}
+		case "disallowed":
+			z.Disallowed, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Disallowed")
+				return
+			}
 		case "fetched_at":
 			z.FetchedAt, err = dc.ReadTime()
 			if err != nil {
@@ -114,12 +122,163 @@ func (z *Document) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Encoding")
 				return
 			}
+		case "charset":
+			z.Charset, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Charset")
+				return
+			}
 		case "link":
 			z.Link, err = dc.ReadString()
 			if err != nil {
 				err = msgp.WrapError(err, "Link")
 				return
 			}
+		case "source":
+			z.Source, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Source")
+				return
+			}
+		case "text":
+			z.Text, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Text")
+				return
+			}
+		case "article":
+			z.Article, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Article")
+				return
+			}
+		case "detected_language":
+			z.DetectedLanguage, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "DetectedLanguage")
+				return
+			}
+		case "language_confidence":
+			z.LanguageConfidence, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "LanguageConfidence")
+				return
+			}
+		case "canonical":
+			z.Canonical, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Canonical")
+				return
+			}
+		case "site_name":
+			z.SiteName, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "SiteName")
+				return
+			}
+		case "author":
+			z.Author, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Author")
+				return
+			}
+		case "keywords":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Keywords")
+				return
+			}
+			if cap(z.Keywords) >= int(zb0002) {
+				z.Keywords = (z.Keywords)[:zb0002]
+			} else {
+				z.Keywords = make([]string, zb0002)
+			}
+			for za0001 := range z.Keywords {
+				z.Keywords[za0001], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Keywords", za0001)
+					return
+				}
+			}
+		case "published_at":
+			z.PublishedAt, err = dc.ReadTime()
+			if err != nil {
+				err = msgp.WrapError(err, "PublishedAt")
+				return
+			}
+		case "modified_at":
+			z.ModifiedAt, err = dc.ReadTime()
+			if err != nil {
+				err = msgp.WrapError(err, "ModifiedAt")
+				return
+			}
+		case "open_graph":
+			var zb0003 uint32
+			zb0003, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "OpenGraph")
+				return
+			}
+			if z.OpenGraph == nil {
+				z.OpenGraph = make(map[string]string, zb0003)
+			} else if len(z.OpenGraph) > 0 {
+				for key := range z.OpenGraph {
+					delete(z.OpenGraph, key)
+				}
+			}
+			for zb0003 > 0 {
+				zb0003--
+				var za0002 string
+				var za0003 string
+				za0002, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "OpenGraph")
+					return
+				}
+				za0003, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "OpenGraph", za0002)
+					return
+				}
+				z.OpenGraph[za0002] = za0003
+			}
+		case "twitter_card":
+			var zb0004 uint32
+			zb0004, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "TwitterCard")
+				return
+			}
+			if z.TwitterCard == nil {
+				z.TwitterCard = make(map[string]string, zb0004)
+			} else if len(z.TwitterCard) > 0 {
+				for key := range z.TwitterCard {
+					delete(z.TwitterCard, key)
+				}
+			}
+			for zb0004 > 0 {
+				zb0004--
+				var za0004 string
+				var za0005 string
+				za0004, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "TwitterCard")
+					return
+				}
+				za0005, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "TwitterCard", za0004)
+					return
+				}
+				z.TwitterCard[za0004] = za0005
+			}
+		case "linked_data":
+			z.LinkedData, err = dc.ReadBytes(z.LinkedData)
+			if err != nil {
+				err = msgp.WrapError(err, "LinkedData")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -133,27 +292,122 @@ func (z *Document) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *Document) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 16
-	// write "etag"
-	err = en.Append(0xde, 0x0, 0x10, 0xa4, 0x65, 0x74, 0x61, 0x67)
-	if err != nil {
-		return
+	// omitempty: check for empty values
+	zb0001Len := uint32(32)
+	var zb0001Mask uint32 /* 32 bits */
+	_ = zb0001Mask
+	if z.ETag == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1
 	}
-	err = en.WriteString(z.ETag)
-	if err != nil {
-		err = msgp.WrapError(err, "ETag")
-		return
+	if z.LastModified == "" {
+		zb0001Len--
+		zb0001Mask |= 0x2
 	}
-	// write "last_modified"
-	err = en.Append(0xad, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64)
+	if z.StatusCode == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x8
+	}
+	if z.Error == "" {
+		zb0001Len--
+		zb0001Mask |= 0x10
+	}
+	if z.Disallowed == false {
+		zb0001Len--
+		zb0001Mask |= 0x20
+	}
+	if z.Charset == "" {
+		zb0001Len--
+		zb0001Mask |= 0x10000
+	}
+	if z.Source == "" {
+		zb0001Len--
+		zb0001Mask |= 0x40000
+	}
+	if z.Text == "" {
+		zb0001Len--
+		zb0001Mask |= 0x80000
+	}
+	if z.Article == "" {
+		zb0001Len--
+		zb0001Mask |= 0x100000
+	}
+	if z.DetectedLanguage == "" {
+		zb0001Len--
+		zb0001Mask |= 0x200000
+	}
+	if z.LanguageConfidence == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x400000
+	}
+	if z.Canonical == "" {
+		zb0001Len--
+		zb0001Mask |= 0x800000
+	}
+	if z.SiteName == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1000000
+	}
+	if z.Author == "" {
+		zb0001Len--
+		zb0001Mask |= 0x2000000
+	}
+	if z.Keywords == nil {
+		zb0001Len--
+		zb0001Mask |= 0x4000000
+	}
+	if z.PublishedAt == (time.Time{}) {
+		zb0001Len--
+		zb0001Mask |= 0x8000000
+	}
+	if z.ModifiedAt == (time.Time{}) {
+		zb0001Len--
+		zb0001Mask |= 0x10000000
+	}
+	if z.OpenGraph == nil {
+		zb0001Len--
+		zb0001Mask |= 0x20000000
+	}
+	if z.TwitterCard == nil {
+		zb0001Len--
+		zb0001Mask |= 0x40000000
+	}
+	if z.LinkedData == nil {
+		zb0001Len--
+		zb0001Mask |= 0x80000000
+	}
+	// variable map header, size zb0001Len
+	err = en.WriteMapHeader(zb0001Len)
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.LastModified)
-	if err != nil {
-		err = msgp.WrapError(err, "LastModified")
+	if zb0001Len == 0 {
 		return
 	}
+	if (zb0001Mask & 0x1) == 0 { // if not empty
+		// write "etag"
+		err = en.Append(0xa4, 0x65, 0x74, 0x61, 0x67)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.ETag)
+		if err != nil {
+			err = msgp.WrapError(err, "ETag")
+			return
+		}
+	}
+	if (zb0001Mask & 0x2) == 0 { // if not empty
+		// write "last_modified"
+		err = en.Append(0xad, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.LastModified)
+		if err != nil {
+			err = msgp.WrapError(err, "LastModified")
+			return
+		}
+	}
 	// write "active"
 	err = en.Append(0xa6, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65)
 	if err != nil {
@@ -164,25 +418,41 @@ func (z *Document) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Active")
 		return
 	}
-	// write "status_code"
-	err = en.Append(0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
-	if err != nil {
-		return
-	}
-	err = en.WriteInt(z.StatusCode)
-	if err != nil {
-		err = msgp.WrapError(err, "StatusCode")
-		return
+	if (zb0001Mask & 0x8) == 0 { // if not empty
+		// write "status_code"
+		err = en.Append(0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteInt(z.StatusCode)
+		if err != nil {
+			err = msgp.WrapError(err, "StatusCode")
+			return
+		}
 	}
-	// write "error"
-	err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
-	if err != nil {
-		return
+	if (zb0001Mask & 0x10) == 0 { // if not empty
+		// write "error"
+		err = en.Append(0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Error)
+		if err != nil {
+			err = msgp.WrapError(err, "Error")
+			return
+		}
 	}
-	err = en.WriteString(z.Error)
-	if err != nil {
-		err = msgp.WrapError(err, "Error")
-		return
+	if (zb0001Mask & 0x20) == 0 { // if not empty
+		// write "disallowed"
+		err = en.Append(0xaa, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64)
+		if err != nil {
+			return
+		}
+		err = en.WriteBool(z.Disallowed)
+		if err != nil {
+			err = msgp.WrapError(err, "Disallowed")
+			return
+		}
 	}
 	// write "fetched_at"
 	err = en.Append(0xaa, 0x66, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74)
@@ -284,6 +554,18 @@ func (z *Document) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Encoding")
 		return
 	}
+	if (zb0001Mask & 0x10000) == 0 { // if not empty
+		// write "charset"
+		err = en.Append(0xa7, 0x63, 0x68, 0x61, 0x72, 0x73, 0x65, 0x74)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Charset)
+		if err != nil {
+			err = msgp.WrapError(err, "Charset")
+			return
+		}
+	}
 	// write "link"
 	err = en.Append(0xa4, 0x6c, 0x69, 0x6e, 0x6b)
 	if err != nil {
@@ -294,28 +576,328 @@ func (z *Document) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Link")
 		return
 	}
+	if (zb0001Mask & 0x40000) == 0 { // if not empty
+		// write "source"
+		err = en.Append(0xa6, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Source)
+		if err != nil {
+			err = msgp.WrapError(err, "Source")
+			return
+		}
+	}
+	if (zb0001Mask & 0x80000) == 0 { // if not empty
+		// write "text"
+		err = en.Append(0xa4, 0x74, 0x65, 0x78, 0x74)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Text)
+		if err != nil {
+			err = msgp.WrapError(err, "Text")
+			return
+		}
+	}
+	if (zb0001Mask & 0x100000) == 0 { // if not empty
+		// write "article"
+		err = en.Append(0xa7, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Article)
+		if err != nil {
+			err = msgp.WrapError(err, "Article")
+			return
+		}
+	}
+	if (zb0001Mask & 0x200000) == 0 { // if not empty
+		// write "detected_language"
+		err = en.Append(0xb1, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.DetectedLanguage)
+		if err != nil {
+			err = msgp.WrapError(err, "DetectedLanguage")
+			return
+		}
+	}
+	if (zb0001Mask & 0x400000) == 0 { // if not empty
+		// write "language_confidence"
+		err = en.Append(0xb3, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteFloat64(z.LanguageConfidence)
+		if err != nil {
+			err = msgp.WrapError(err, "LanguageConfidence")
+			return
+		}
+	}
+	if (zb0001Mask & 0x800000) == 0 { // if not empty
+		// write "canonical"
+		err = en.Append(0xa9, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Canonical)
+		if err != nil {
+			err = msgp.WrapError(err, "Canonical")
+			return
+		}
+	}
+	if (zb0001Mask & 0x1000000) == 0 { // if not empty
+		// write "site_name"
+		err = en.Append(0xa9, 0x73, 0x69, 0x74, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.SiteName)
+		if err != nil {
+			err = msgp.WrapError(err, "SiteName")
+			return
+		}
+	}
+	if (zb0001Mask & 0x2000000) == 0 { // if not empty
+		// write "author"
+		err = en.Append(0xa6, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Author)
+		if err != nil {
+			err = msgp.WrapError(err, "Author")
+			return
+		}
+	}
+	if (zb0001Mask & 0x4000000) == 0 { // if not empty
+		// write "keywords"
+		err = en.Append(0xa8, 0x6b, 0x65, 0x79, 0x77, 0x6f, 0x72, 0x64, 0x73)
+		if err != nil {
+			return
+		}
+		err = en.WriteArrayHeader(uint32(len(z.Keywords)))
+		if err != nil {
+			err = msgp.WrapError(err, "Keywords")
+			return
+		}
+		for za0001 := range z.Keywords {
+			err = en.WriteString(z.Keywords[za0001])
+			if err != nil {
+				err = msgp.WrapError(err, "Keywords", za0001)
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x8000000) == 0 { // if not empty
+		// write "published_at"
+		err = en.Append(0xac, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74)
+		if err != nil {
+			return
+		}
+		err = en.WriteTime(z.PublishedAt)
+		if err != nil {
+			err = msgp.WrapError(err, "PublishedAt")
+			return
+		}
+	}
+	if (zb0001Mask & 0x10000000) == 0 { // if not empty
+		// write "modified_at"
+		err = en.Append(0xab, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f, 0x61, 0x74)
+		if err != nil {
+			return
+		}
+		err = en.WriteTime(z.ModifiedAt)
+		if err != nil {
+			err = msgp.WrapError(err, "ModifiedAt")
+			return
+		}
+	}
+	if (zb0001Mask & 0x20000000) == 0 { // if not empty
+		// write "open_graph"
+		err = en.Append(0xaa, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68)
+		if err != nil {
+			return
+		}
+		err = en.WriteMapHeader(uint32(len(z.OpenGraph)))
+		if err != nil {
+			err = msgp.WrapError(err, "OpenGraph")
+			return
+		}
+		for za0002, za0003 := range z.OpenGraph {
+			err = en.WriteString(za0002)
+			if err != nil {
+				err = msgp.WrapError(err, "OpenGraph")
+				return
+			}
+			err = en.WriteString(za0003)
+			if err != nil {
+				err = msgp.WrapError(err, "OpenGraph", za0002)
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x40000000) == 0 { // if not empty
+		// write "twitter_card"
+		err = en.Append(0xac, 0x74, 0x77, 0x69, 0x74, 0x74, 0x65, 0x72, 0x5f, 0x63, 0x61, 0x72, 0x64)
+		if err != nil {
+			return
+		}
+		err = en.WriteMapHeader(uint32(len(z.TwitterCard)))
+		if err != nil {
+			err = msgp.WrapError(err, "TwitterCard")
+			return
+		}
+		for za0004, za0005 := range z.TwitterCard {
+			err = en.WriteString(za0004)
+			if err != nil {
+				err = msgp.WrapError(err, "TwitterCard")
+				return
+			}
+			err = en.WriteString(za0005)
+			if err != nil {
+				err = msgp.WrapError(err, "TwitterCard", za0004)
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x80000000) == 0 { // if not empty
+		// write "linked_data"
+		err = en.Append(0xab, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x64, 0x5f, 0x64, 0x61, 0x74, 0x61)
+		if err != nil {
+			return
+		}
+		err = en.WriteBytes(z.LinkedData)
+		if err != nil {
+			err = msgp.WrapError(err, "LinkedData")
+			return
+		}
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *Document) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 16
-	// string "etag"
-	o = append(o, 0xde, 0x0, 0x10, 0xa4, 0x65, 0x74, 0x61, 0x67)
-	o = msgp.AppendString(o, z.ETag)
-	// string "last_modified"
-	o = append(o, 0xad, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64)
-	o = msgp.AppendString(o, z.LastModified)
+	// omitempty: check for empty values
+	zb0001Len := uint32(32)
+	var zb0001Mask uint32 /* 32 bits */
+	_ = zb0001Mask
+	if z.ETag == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1
+	}
+	if z.LastModified == "" {
+		zb0001Len--
+		zb0001Mask |= 0x2
+	}
+	if z.StatusCode == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x8
+	}
+	if z.Error == "" {
+		zb0001Len--
+		zb0001Mask |= 0x10
+	}
+	if z.Disallowed == false {
+		zb0001Len--
+		zb0001Mask |= 0x20
+	}
+	if z.Charset == "" {
+		zb0001Len--
+		zb0001Mask |= 0x10000
+	}
+	if z.Source == "" {
+		zb0001Len--
+		zb0001Mask |= 0x40000
+	}
+	if z.Text == "" {
+		zb0001Len--
+		zb0001Mask |= 0x80000
+	}
+	if z.Article == "" {
+		zb0001Len--
+		zb0001Mask |= 0x100000
+	}
+	if z.DetectedLanguage == "" {
+		zb0001Len--
+		zb0001Mask |= 0x200000
+	}
+	if z.LanguageConfidence == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x400000
+	}
+	if z.Canonical == "" {
+		zb0001Len--
+		zb0001Mask |= 0x800000
+	}
+	if z.SiteName == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1000000
+	}
+	if z.Author == "" {
+		zb0001Len--
+		zb0001Mask |= 0x2000000
+	}
+	if z.Keywords == nil {
+		zb0001Len--
+		zb0001Mask |= 0x4000000
+	}
+	if z.PublishedAt == (time.Time{}) {
+		zb0001Len--
+		zb0001Mask |= 0x8000000
+	}
+	if z.ModifiedAt == (time.Time{}) {
+		zb0001Len--
+		zb0001Mask |= 0x10000000
+	}
+	if z.OpenGraph == nil {
+		zb0001Len--
+		zb0001Mask |= 0x20000000
+	}
+	if z.TwitterCard == nil {
+		zb0001Len--
+		zb0001Mask |= 0x40000000
+	}
+	if z.LinkedData == nil {
+		zb0001Len--
+		zb0001Mask |= 0x80000000
+	}
+	// variable map header, size zb0001Len
+	o = msgp.AppendMapHeader(o, zb0001Len)
+	if zb0001Len == 0 {
+		return
+	}
+	if (zb0001Mask & 0x1) == 0 { // if not empty
+		// string "etag"
+		o = append(o, 0xa4, 0x65, 0x74, 0x61, 0x67)
+		o = msgp.AppendString(o, z.ETag)
+	}
+	if (zb0001Mask & 0x2) == 0 { // if not empty
+		// string "last_modified"
+		o = append(o, 0xad, 0x6c, 0x61, 0x73, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64)
+		o = msgp.AppendString(o, z.LastModified)
+	}
 	// string "active"
 	o = append(o, 0xa6, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65)
 	o = msgp.AppendBool(o, z.Active)
-	// string "status_code"
-	o = append(o, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
-	o = msgp.AppendInt(o, z.StatusCode)
-	// string "error"
-	o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
-	o = msgp.AppendString(o, z.Error)
+	if (zb0001Mask & 0x8) == 0 { // if not empty
+		// string "status_code"
+		o = append(o, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
+		o = msgp.AppendInt(o, z.StatusCode)
+	}
+	if (zb0001Mask & 0x10) == 0 { // if not empty
+		// string "error"
+		o = append(o, 0xa5, 0x65, 0x72, 0x72, 0x6f, 0x72)
+		o = msgp.AppendString(o, z.Error)
+	}
+	if (zb0001Mask & 0x20) == 0 { // if not empty
+		// string "disallowed"
+		o = append(o, 0xaa, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64)
+		o = msgp.AppendBool(o, z.Disallowed)
+	}
 	// string "fetched_at"
 	o = append(o, 0xaa, 0x66, 0x65, 0x74, 0x63, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74)
 	o = msgp.AppendTime(o, z.FetchedAt)
@@ -346,9 +928,95 @@ func (z *Document) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "encoding"
 	o = append(o, 0xa8, 0x65, 0x6e, 0x63, 0x6f, 0x64, 0x69, 0x6e, 0x67)
 	o = msgp.AppendString(o, z.Encoding)
+	if (zb0001Mask & 0x10000) == 0 { // if not empty
+		// string "charset"
+		o = append(o, 0xa7, 0x63, 0x68, 0x61, 0x72, 0x73, 0x65, 0x74)
+		o = msgp.AppendString(o, z.Charset)
+	}
 	// string "link"
 	o = append(o, 0xa4, 0x6c, 0x69, 0x6e, 0x6b)
 	o = msgp.AppendString(o, z.Link)
+	if (zb0001Mask & 0x40000) == 0 { // if not empty
+		// string "source"
+		o = append(o, 0xa6, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65)
+		o = msgp.AppendString(o, z.Source)
+	}
+	if (zb0001Mask & 0x80000) == 0 { // if not empty
+		// string "text"
+		o = append(o, 0xa4, 0x74, 0x65, 0x78, 0x74)
+		o = msgp.AppendString(o, z.Text)
+	}
+	if (zb0001Mask & 0x100000) == 0 { // if not empty
+		// string "article"
+		o = append(o, 0xa7, 0x61, 0x72, 0x74, 0x69, 0x63, 0x6c, 0x65)
+		o = msgp.AppendString(o, z.Article)
+	}
+	if (zb0001Mask & 0x200000) == 0 { // if not empty
+		// string "detected_language"
+		o = append(o, 0xb1, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65)
+		o = msgp.AppendString(o, z.DetectedLanguage)
+	}
+	if (zb0001Mask & 0x400000) == 0 { // if not empty
+		// string "language_confidence"
+		o = append(o, 0xb3, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65)
+		o = msgp.AppendFloat64(o, z.LanguageConfidence)
+	}
+	if (zb0001Mask & 0x800000) == 0 { // if not empty
+		// string "canonical"
+		o = append(o, 0xa9, 0x63, 0x61, 0x6e, 0x6f, 0x6e, 0x69, 0x63, 0x61, 0x6c)
+		o = msgp.AppendString(o, z.Canonical)
+	}
+	if (zb0001Mask & 0x1000000) == 0 { // if not empty
+		// string "site_name"
+		o = append(o, 0xa9, 0x73, 0x69, 0x74, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65)
+		o = msgp.AppendString(o, z.SiteName)
+	}
+	if (zb0001Mask & 0x2000000) == 0 { // if not empty
+		// string "author"
+		o = append(o, 0xa6, 0x61, 0x75, 0x74, 0x68, 0x6f, 0x72)
+		o = msgp.AppendString(o, z.Author)
+	}
+	if (zb0001Mask & 0x4000000) == 0 { // if not empty
+		// string "keywords"
+		o = append(o, 0xa8, 0x6b, 0x65, 0x79, 0x77, 0x6f, 0x72, 0x64, 0x73)
+		o = msgp.AppendArrayHeader(o, uint32(len(z.Keywords)))
+		for za0001 := range z.Keywords {
+			o = msgp.AppendString(o, z.Keywords[za0001])
+		}
+	}
+	if (zb0001Mask & 0x8000000) == 0 { // if not empty
+		// string "published_at"
+		o = append(o, 0xac, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74)
+		o = msgp.AppendTime(o, z.PublishedAt)
+	}
+	if (zb0001Mask & 0x10000000) == 0 { // if not empty
+		// string "modified_at"
+		o = append(o, 0xab, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64, 0x5f, 0x61, 0x74)
+		o = msgp.AppendTime(o, z.ModifiedAt)
+	}
+	if (zb0001Mask & 0x20000000) == 0 { // if not empty
+		// string "open_graph"
+		o = append(o, 0xaa, 0x6f, 0x70, 0x65, 0x6e, 0x5f, 0x67, 0x72, 0x61, 0x70, 0x68)
+		o = msgp.AppendMapHeader(o, uint32(len(z.OpenGraph)))
+		for za0002, za0003 := range z.OpenGraph {
+			o = msgp.AppendString(o, za0002)
+			o = msgp.AppendString(o, za0003)
+		}
+	}
+	if (zb0001Mask & 0x40000000) == 0 { // if not empty
+		// string "twitter_card"
+		o = append(o, 0xac, 0x74, 0x77, 0x69, 0x74, 0x74, 0x65, 0x72, 0x5f, 0x63, 0x61, 0x72, 0x64)
+		o = msgp.AppendMapHeader(o, uint32(len(z.TwitterCard)))
+		for za0004, za0005 := range z.TwitterCard {
+			o = msgp.AppendString(o, za0004)
+			o = msgp.AppendString(o, za0005)
+		}
+	}
+	if (zb0001Mask & 0x80000000) == 0 { // if not empty
+		// string "linked_data"
+		o = append(o, 0xab, 0x6c, 0x69, 0x6e, 0x6b, 0x65, 0x64, 0x5f, 0x64, 0x61, 0x74, 0x61)
+		o = msgp.AppendBytes(o, z.LinkedData)
+	}
 	return
 }
 
@@ -400,6 +1068,12 @@ func (z *Document) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Error")
 				return
 			}
+		case "disallowed":
+			z.Disallowed, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Disallowed")
+				return
+			}
 		case "fetched_at":
 			z.FetchedAt, bts, err = msgp.ReadTimeBytes(bts)
 			if err != nil {
@@ -442,28 +1116,179 @@ func (z *Document) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Title")
 				return
 			}
-		case "description":
-			z.Description, bts, err = msgp.ReadStringBytes(bts)
+		case "description":
+			z.Description, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Description")
+				return
+			}
+		case "content":
+			z.Content, bts, err = msgp.ReadBytesBytes(bts, z.Content)
+			if err != nil {
+				err = msgp.WrapError(err, "Content")
+				return
+			}
+		case "encoding":
+			z.Encoding, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Encoding")
+				return
+			}
+		case "charset":
+			z.Charset, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Charset")
+				return
+			}
+		case "link":
+			z.Link, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Link")
+				return
+			}
+		case "source":
+			z.Source, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Source")
+				return
+			}
+		case "text":
+			z.Text, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Text")
+				return
+			}
+		case "article":
+			z.Article, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Article")
+				return
+			}
+		case "detected_language":
+			z.DetectedLanguage, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "DetectedLanguage")
+				return
+			}
+		case "language_confidence":
+			z.LanguageConfidence, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "LanguageConfidence")
+				return
+			}
+		case "canonical":
+			z.Canonical, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Canonical")
+				return
+			}
+		case "site_name":
+			z.SiteName, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "SiteName")
+				return
+			}
+		case "author":
+			z.Author, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Author")
+				return
+			}
+		case "keywords":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Keywords")
+				return
+			}
+			if cap(z.Keywords) >= int(zb0002) {
+				z.Keywords = (z.Keywords)[:zb0002]
+			} else {
+				z.Keywords = make([]string, zb0002)
+			}
+			for za0001 := range z.Keywords {
+				z.Keywords[za0001], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Keywords", za0001)
+					return
+				}
+			}
+		case "published_at":
+			z.PublishedAt, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PublishedAt")
+				return
+			}
+		case "modified_at":
+			z.ModifiedAt, bts, err = msgp.ReadTimeBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Description")
+				err = msgp.WrapError(err, "ModifiedAt")
 				return
 			}
-		case "content":
-			z.Content, bts, err = msgp.ReadBytesBytes(bts, z.Content)
+		case "open_graph":
+			var zb0003 uint32
+			zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Content")
+				err = msgp.WrapError(err, "OpenGraph")
 				return
 			}
-		case "encoding":
-			z.Encoding, bts, err = msgp.ReadStringBytes(bts)
+			if z.OpenGraph == nil {
+				z.OpenGraph = make(map[string]string, zb0003)
+			} else if len(z.OpenGraph) > 0 {
+				for key := range z.OpenGraph {
+					delete(z.OpenGraph, key)
+				}
+			}
+			for zb0003 > 0 {
+				var za0002 string
+				var za0003 string
+				zb0003--
+				za0002, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "OpenGraph")
+					return
+				}
+				za0003, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "OpenGraph", za0002)
+					return
+				}
+				z.OpenGraph[za0002] = za0003
+			}
+		case "twitter_card":
+			var zb0004 uint32
+			zb0004, bts, err = msgp.ReadMapHeaderBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Encoding")
+				err = msgp.WrapError(err, "TwitterCard")
 				return
 			}
-		case "link":
-			z.Link, bts, err = msgp.ReadStringBytes(bts)
+			if z.TwitterCard == nil {
+				z.TwitterCard = make(map[string]string, zb0004)
+			} else if len(z.TwitterCard) > 0 {
+				for key := range z.TwitterCard {
+					delete(z.TwitterCard, key)
+				}
+			}
+			for zb0004 > 0 {
+				var za0004 string
+				var za0005 string
+				zb0004--
+				za0004, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "TwitterCard")
+					return
+				}
+				za0005, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "TwitterCard", za0004)
+					return
+				}
+				z.TwitterCard[za0004] = za0005
+			}
+		case "linked_data":
+			z.LinkedData, bts, err = msgp.ReadBytesBytes(bts, z.LinkedData)
 			if err != nil {
-				err = msgp.WrapError(err, "Link")
+				err = msgp.WrapError(err, "LinkedData")
 				return
 			}
 		default:
@@ -480,7 +1305,25 @@ func (z *Document) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *Document) Msgsize() (s int) {
-	s = 3 + 5 + msgp.StringPrefixSize + len(z.ETag) + 14 + msgp.StringPrefixSize + len(z.LastModified) + 7 + msgp.BoolSize + 12 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Error) + 11 + msgp.TimeSize + 8 + msgp.StringPrefixSize + len(z.FeedID) + 9 + msgp.StringPrefixSize + len(z.Language) + 5 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Month) + 4 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Title) + 12 + msgp.StringPrefixSize + len(z.Description) + 8 + msgp.BytesPrefixSize + len(z.Content) + 9 + msgp.StringPrefixSize + len(z.Encoding) + 5 + msgp.StringPrefixSize + len(z.Link)
+	s = 3 + 5 + msgp.StringPrefixSize + len(z.ETag) + 14 + msgp.StringPrefixSize + len(z.LastModified) + 7 + msgp.BoolSize + 12 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Error) + 11 + msgp.BoolSize + 11 + msgp.TimeSize + 8 + msgp.StringPrefixSize + len(z.FeedID) + 9 + msgp.StringPrefixSize + len(z.Language) + 5 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Month) + 4 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Title) + 12 + msgp.StringPrefixSize + len(z.Description) + 8 + msgp.BytesPrefixSize + len(z.Content) + 9 + msgp.StringPrefixSize + len(z.Encoding) + 8 + msgp.StringPrefixSize + len(z.Charset) + 5 + msgp.StringPrefixSize + len(z.Link) + 7 + msgp.StringPrefixSize + len(z.Source) + 5 + msgp.StringPrefixSize + len(z.Text) + 8 + msgp.StringPrefixSize + len(z.Article) + 18 + msgp.StringPrefixSize + len(z.DetectedLanguage) + 20 + msgp.Float64Size + 10 + msgp.StringPrefixSize + len(z.Canonical) + 10 + msgp.StringPrefixSize + len(z.SiteName) + 7 + msgp.StringPrefixSize + len(z.Author) + 9 + msgp.ArrayHeaderSize
+	for za0001 := range z.Keywords {
+		s += msgp.StringPrefixSize + len(z.Keywords[za0001])
+	}
+	s += 13 + msgp.TimeSize + 12 + msgp.TimeSize + 11 + msgp.MapHeaderSize
+	if z.OpenGraph != nil {
+		for za0002, za0003 := range z.OpenGraph {
+			_ = za0003
+			s += msgp.StringPrefixSize + len(za0002) + msgp.StringPrefixSize + len(za0003)
+		}
+	}
+	s += 13 + msgp.MapHeaderSize
+	if z.TwitterCard != nil {
+		for za0004, za0005 := range z.TwitterCard {
+			_ = za0005
+			s += msgp.StringPrefixSize + len(za0004) + msgp.StringPrefixSize + len(za0005)
+		}
+	}
+	s += 12 + msgp.BytesPrefixSize + len(z.LinkedData)
 	return
 }
 
@@ -526,6 +1369,12 @@ func (z *FeedItem) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Content")
 				return
 			}
+		case "content_text":
+			z.ContentText, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "ContentText")
+				return
+			}
 		case "link":
 			z.Link, err = dc.ReadString()
 			if err != nil {
@@ -613,6 +1462,18 @@ func (z *FeedItem) DecodeMsg(dc *msgp.Reader) (err error) {
 					return
 				}
 			}
+		case "language":
+			z.Language, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Language")
+				return
+			}
+		case "published_at":
+			z.PublishedAt, err = dc.ReadTime()
+			if err != nil {
+				err = msgp.WrapError(err, "PublishedAt")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -626,9 +1487,28 @@ func (z *FeedItem) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *FeedItem) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 12
+	// omitempty: check for empty values
+	zb0001Len := uint32(15)
+	var zb0001Mask uint16 /* 15 bits */
+	_ = zb0001Mask
+	if z.ContentText == "" {
+		zb0001Len--
+		zb0001Mask |= 0x10
+	}
+	if z.Language == "" {
+		zb0001Len--
+		zb0001Mask |= 0x2000
+	}
+	// variable map header, size zb0001Len
+	err = en.Append(0x80 | uint8(zb0001Len))
+	if err != nil {
+		return
+	}
+	if zb0001Len == 0 {
+		return
+	}
 	// write "feed_id"
-	err = en.Append(0x8c, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
+	err = en.Append(0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
 	if err != nil {
 		return
 	}
@@ -667,6 +1547,18 @@ func (z *FeedItem) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Content")
 		return
 	}
+	if (zb0001Mask & 0x10) == 0 { // if not empty
+		// write "content_text"
+		err = en.Append(0xac, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x65, 0x78, 0x74)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.ContentText)
+		if err != nil {
+			err = msgp.WrapError(err, "ContentText")
+			return
+		}
+	}
 	// write "link"
 	err = en.Append(0xa4, 0x6c, 0x69, 0x6e, 0x6b)
 	if err != nil {
@@ -768,15 +1660,53 @@ func (z *FeedItem) EncodeMsg(en *msgp.Writer) (err error) {
 			return
 		}
 	}
+	if (zb0001Mask & 0x2000) == 0 { // if not empty
+		// write "language"
+		err = en.Append(0xa8, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Language)
+		if err != nil {
+			err = msgp.WrapError(err, "Language")
+			return
+		}
+	}
+	// write "published_at"
+	err = en.Append(0xac, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteTime(z.PublishedAt)
+	if err != nil {
+		err = msgp.WrapError(err, "PublishedAt")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *FeedItem) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 12
+	// omitempty: check for empty values
+	zb0001Len := uint32(15)
+	var zb0001Mask uint16 /* 15 bits */
+	_ = zb0001Mask
+	if z.ContentText == "" {
+		zb0001Len--
+		zb0001Mask |= 0x10
+	}
+	if z.Language == "" {
+		zb0001Len--
+		zb0001Mask |= 0x2000
+	}
+	// variable map header, size zb0001Len
+	o = append(o, 0x80|uint8(zb0001Len))
+	if zb0001Len == 0 {
+		return
+	}
 	// string "feed_id"
-	o = append(o, 0x8c, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
+	o = append(o, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
 	o = msgp.AppendString(o, z.FeedID)
 	// string "title"
 	o = append(o, 0xa5, 0x74, 0x69, 0x74, 0x6c, 0x65)
@@ -787,6 +1717,11 @@ func (z *FeedItem) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "content"
 	o = append(o, 0xa7, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74)
 	o = msgp.AppendString(o, z.Content)
+	if (zb0001Mask & 0x10) == 0 { // if not empty
+		// string "content_text"
+		o = append(o, 0xac, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x65, 0x78, 0x74)
+		o = msgp.AppendString(o, z.ContentText)
+	}
 	// string "link"
 	o = append(o, 0xa4, 0x6c, 0x69, 0x6e, 0x6b)
 	o = msgp.AppendString(o, z.Link)
@@ -820,6 +1755,14 @@ func (z *FeedItem) MarshalMsg(b []byte) (o []byte, err error) {
 	for za0003 := range z.Enclosures {
 		o = msgp.AppendString(o, z.Enclosures[za0003])
 	}
+	if (zb0001Mask & 0x2000) == 0 { // if not empty
+		// string "language"
+		o = append(o, 0xa8, 0x6c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65)
+		o = msgp.AppendString(o, z.Language)
+	}
+	// string "published_at"
+	o = append(o, 0xac, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x73, 0x68, 0x65, 0x64, 0x5f, 0x61, 0x74)
+	o = msgp.AppendTime(o, z.PublishedAt)
 	return
 }
 
@@ -865,6 +1808,12 @@ func (z *FeedItem) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Content")
 				return
 			}
+		case "content_text":
+			z.ContentText, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ContentText")
+				return
+			}
 		case "link":
 			z.Link, bts, err = msgp.ReadStringBytes(bts)
 			if err != nil {
@@ -952,6 +1901,18 @@ func (z *FeedItem) UnmarshalMsg(bts []byte) (o []byte, err error) {
 					return
 				}
 			}
+		case "language":
+			z.Language, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Language")
+				return
+			}
+		case "published_at":
+			z.PublishedAt, bts, err = msgp.ReadTimeBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PublishedAt")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -966,7 +1927,7 @@ func (z *FeedItem) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *FeedItem) Msgsize() (s int) {
-	s = 1 + 8 + msgp.StringPrefixSize + len(z.FeedID) + 6 + msgp.StringPrefixSize + len(z.Title) + 12 + msgp.StringPrefixSize + len(z.Description) + 8 + msgp.StringPrefixSize + len(z.Content) + 5 + msgp.StringPrefixSize + len(z.Link) + 8 + msgp.StringPrefixSize + len(z.Updated) + 10 + msgp.StringPrefixSize + len(z.Published) + 5 + msgp.StringPrefixSize + len(z.GUID) + 8 + msgp.ArrayHeaderSize
+	s = 1 + 8 + msgp.StringPrefixSize + len(z.FeedID) + 6 + msgp.StringPrefixSize + len(z.Title) + 12 + msgp.StringPrefixSize + len(z.Description) + 8 + msgp.StringPrefixSize + len(z.Content) + 13 + msgp.StringPrefixSize + len(z.ContentText) + 5 + msgp.StringPrefixSize + len(z.Link) + 8 + msgp.StringPrefixSize + len(z.Updated) + 10 + msgp.StringPrefixSize + len(z.Published) + 5 + msgp.StringPrefixSize + len(z.GUID) + 8 + msgp.ArrayHeaderSize
 	for za0001 := range z.Authors {
 		s += msgp.StringPrefixSize + len(z.Authors[za0001])
 	}
@@ -978,6 +1939,7 @@ func (z *FeedItem) Msgsize() (s int) {
 	for za0003 := range z.Enclosures {
 		s += msgp.StringPrefixSize + len(z.Enclosures[za0003])
 	}
+	s += 9 + msgp.StringPrefixSize + len(z.Language) + 13 + msgp.TimeSize
 	return
 }
 
@@ -1023,6 +1985,36 @@ func (z *FeedSync) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Active")
 				return
 			}
+		case "not_modified":
+			z.NotModified, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "NotModified")
+				return
+			}
+		case "disallowed":
+			z.Disallowed, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Disallowed")
+				return
+			}
+		case "deactivated":
+			z.Deactivated, err = dc.ReadBool()
+			if err != nil {
+				err = msgp.WrapError(err, "Deactivated")
+				return
+			}
+		case "reason":
+			z.Reason, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Reason")
+				return
+			}
+		case "failures":
+			z.Failures, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Failures")
+				return
+			}
 		case "status_code":
 			z.StatusCode, err = dc.ReadInt()
 			if err != nil {
@@ -1164,9 +2156,40 @@ func (z *FeedSync) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *FeedSync) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 21
+	// omitempty: check for empty values
+	zb0001Len := uint32(26)
+	var zb0001Mask uint32 /* 26 bits */
+	_ = zb0001Mask
+	if z.NotModified == false {
+		zb0001Len--
+		zb0001Mask |= 0x10
+	}
+	if z.Disallowed == false {
+		zb0001Len--
+		zb0001Mask |= 0x20
+	}
+	if z.Deactivated == false {
+		zb0001Len--
+		zb0001Mask |= 0x40
+	}
+	if z.Reason == "" {
+		zb0001Len--
+		zb0001Mask |= 0x80
+	}
+	if z.Failures == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x100
+	}
+	// variable map header, size zb0001Len
+	err = en.WriteMapHeader(zb0001Len)
+	if err != nil {
+		return
+	}
+	if zb0001Len == 0 {
+		return
+	}
 	// write "feed_id"
-	err = en.Append(0xde, 0x0, 0x15, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
+	err = en.Append(0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
 	if err != nil {
 		return
 	}
@@ -1205,6 +2228,66 @@ func (z *FeedSync) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Active")
 		return
 	}
+	if (zb0001Mask & 0x10) == 0 { // if not empty
+		// write "not_modified"
+		err = en.Append(0xac, 0x6e, 0x6f, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64)
+		if err != nil {
+			return
+		}
+		err = en.WriteBool(z.NotModified)
+		if err != nil {
+			err = msgp.WrapError(err, "NotModified")
+			return
+		}
+	}
+	if (zb0001Mask & 0x20) == 0 { // if not empty
+		// write "disallowed"
+		err = en.Append(0xaa, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64)
+		if err != nil {
+			return
+		}
+		err = en.WriteBool(z.Disallowed)
+		if err != nil {
+			err = msgp.WrapError(err, "Disallowed")
+			return
+		}
+	}
+	if (zb0001Mask & 0x40) == 0 { // if not empty
+		// write "deactivated"
+		err = en.Append(0xab, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64)
+		if err != nil {
+			return
+		}
+		err = en.WriteBool(z.Deactivated)
+		if err != nil {
+			err = msgp.WrapError(err, "Deactivated")
+			return
+		}
+	}
+	if (zb0001Mask & 0x80) == 0 { // if not empty
+		// write "reason"
+		err = en.Append(0xa6, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.Reason)
+		if err != nil {
+			err = msgp.WrapError(err, "Reason")
+			return
+		}
+	}
+	if (zb0001Mask & 0x100) == 0 { // if not empty
+		// write "failures"
+		err = en.Append(0xa8, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73)
+		if err != nil {
+			return
+		}
+		err = en.WriteInt(z.Failures)
+		if err != nil {
+			err = msgp.WrapError(err, "Failures")
+			return
+		}
+	}
 	// write "status_code"
 	err = en.Append(0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
 	if err != nil {
@@ -1395,9 +2478,37 @@ func (z *FeedSync) EncodeMsg(en *msgp.Writer) (err error) {
 // MarshalMsg implements msgp.Marshaler
 func (z *FeedSync) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 21
+	// omitempty: check for empty values
+	zb0001Len := uint32(26)
+	var zb0001Mask uint32 /* 26 bits */
+	_ = zb0001Mask
+	if z.NotModified == false {
+		zb0001Len--
+		zb0001Mask |= 0x10
+	}
+	if z.Disallowed == false {
+		zb0001Len--
+		zb0001Mask |= 0x20
+	}
+	if z.Deactivated == false {
+		zb0001Len--
+		zb0001Mask |= 0x40
+	}
+	if z.Reason == "" {
+		zb0001Len--
+		zb0001Mask |= 0x80
+	}
+	if z.Failures == 0 {
+		zb0001Len--
+		zb0001Mask |= 0x100
+	}
+	// variable map header, size zb0001Len
+	o = msgp.AppendMapHeader(o, zb0001Len)
+	if zb0001Len == 0 {
+		return
+	}
 	// string "feed_id"
-	o = append(o, 0xde, 0x0, 0x15, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
+	o = append(o, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
 	o = msgp.AppendString(o, z.FeedID)
 	// string "etag"
 	o = append(o, 0xa4, 0x65, 0x74, 0x61, 0x67)
@@ -1408,6 +2519,31 @@ func (z *FeedSync) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "active"
 	o = append(o, 0xa6, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65)
 	o = msgp.AppendBool(o, z.Active)
+	if (zb0001Mask & 0x10) == 0 { // if not empty
+		// string "not_modified"
+		o = append(o, 0xac, 0x6e, 0x6f, 0x74, 0x5f, 0x6d, 0x6f, 0x64, 0x69, 0x66, 0x69, 0x65, 0x64)
+		o = msgp.AppendBool(o, z.NotModified)
+	}
+	if (zb0001Mask & 0x20) == 0 { // if not empty
+		// string "disallowed"
+		o = append(o, 0xaa, 0x64, 0x69, 0x73, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64)
+		o = msgp.AppendBool(o, z.Disallowed)
+	}
+	if (zb0001Mask & 0x40) == 0 { // if not empty
+		// string "deactivated"
+		o = append(o, 0xab, 0x64, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x64)
+		o = msgp.AppendBool(o, z.Deactivated)
+	}
+	if (zb0001Mask & 0x80) == 0 { // if not empty
+		// string "reason"
+		o = append(o, 0xa6, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e)
+		o = msgp.AppendString(o, z.Reason)
+	}
+	if (zb0001Mask & 0x100) == 0 { // if not empty
+		// string "failures"
+		o = append(o, 0xa8, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73)
+		o = msgp.AppendInt(o, z.Failures)
+	}
 	// string "status_code"
 	o = append(o, 0xab, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65)
 	o = msgp.AppendInt(o, z.StatusCode)
@@ -1510,6 +2646,36 @@ func (z *FeedSync) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Active")
 				return
 			}
+		case "not_modified":
+			z.NotModified, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "NotModified")
+				return
+			}
+		case "disallowed":
+			z.Disallowed, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Disallowed")
+				return
+			}
+		case "deactivated":
+			z.Deactivated, bts, err = msgp.ReadBoolBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Deactivated")
+				return
+			}
+		case "reason":
+			z.Reason, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Reason")
+				return
+			}
+		case "failures":
+			z.Failures, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Failures")
+				return
+			}
 		case "status_code":
 			z.StatusCode, bts, err = msgp.ReadIntBytes(bts)
 			if err != nil {
@@ -1652,7 +2818,7 @@ func (z *FeedSync) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *FeedSync) Msgsize() (s int) {
-	s = 3 + 8 + msgp.StringPrefixSize + len(z.FeedID) + 5 + msgp.StringPrefixSize + len(z.ETag) + 14 + msgp.StringPrefixSize + len(z.LastModified) + 7 + msgp.BoolSize + 12 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Error) + 10 + msgp.TimeSize + 11 + msgp.Int64Size + 6 + msgp.StringPrefixSize + len(z.Title) + 12 + msgp.StringPrefixSize + len(z.Description) + 5 + msgp.StringPrefixSize + len(z.Link) + 6 + msgp.ArrayHeaderSize
+	s = 3 + 8 + msgp.StringPrefixSize + len(z.FeedID) + 5 + msgp.StringPrefixSize + len(z.ETag) + 14 + msgp.StringPrefixSize + len(z.LastModified) + 7 + msgp.BoolSize + 13 + msgp.BoolSize + 11 + msgp.BoolSize + 12 + msgp.BoolSize + 7 + msgp.StringPrefixSize + len(z.Reason) + 9 + msgp.IntSize + 12 + msgp.IntSize + 6 + msgp.StringPrefixSize + len(z.Error) + 10 + msgp.TimeSize + 11 + msgp.Int64Size + 6 + msgp.StringPrefixSize + len(z.Title) + 12 + msgp.StringPrefixSize + len(z.Description) + 5 + msgp.StringPrefixSize + len(z.Link) + 6 + msgp.ArrayHeaderSize
 	for za0001 := range z.Links {
 		s += msgp.StringPrefixSize + len(z.Links[za0001])
 	}
@@ -1712,6 +2878,31 @@ func (z *Subscription) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "SiteURL")
 				return
 			}
+		case "categories":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Categories")
+				return
+			}
+			if cap(z.Categories) >= int(zb0002) {
+				z.Categories = (z.Categories)[:zb0002]
+			} else {
+				z.Categories = make([]string, zb0002)
+			}
+			for za0001 := range z.Categories {
+				z.Categories[za0001], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Categories", za0001)
+					return
+				}
+			}
+		case "previous_url":
+			z.PreviousURL, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "PreviousURL")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -1725,17 +2916,42 @@ func (z *Subscription) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *Subscription) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 5
-	// write "feed_id"
-	err = en.Append(0x85, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
+	// omitempty: check for empty values
+	zb0001Len := uint32(7)
+	var zb0001Mask uint8 /* 7 bits */
+	_ = zb0001Mask
+	if z.FeedID == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1
+	}
+	if z.Categories == nil {
+		zb0001Len--
+		zb0001Mask |= 0x20
+	}
+	if z.PreviousURL == "" {
+		zb0001Len--
+		zb0001Mask |= 0x40
+	}
+	// variable map header, size zb0001Len
+	err = en.Append(0x80 | uint8(zb0001Len))
 	if err != nil {
 		return
 	}
-	err = en.WriteString(z.FeedID)
-	if err != nil {
-		err = msgp.WrapError(err, "FeedID")
+	if zb0001Len == 0 {
 		return
 	}
+	if (zb0001Mask & 0x1) == 0 { // if not empty
+		// write "feed_id"
+		err = en.Append(0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.FeedID)
+		if err != nil {
+			err = msgp.WrapError(err, "FeedID")
+			return
+		}
+	}
 	// write "title"
 	err = en.Append(0xa5, 0x74, 0x69, 0x74, 0x6c, 0x65)
 	if err != nil {
@@ -1776,16 +2992,69 @@ func (z *Subscription) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "SiteURL")
 		return
 	}
+	if (zb0001Mask & 0x20) == 0 { // if not empty
+		// write "categories"
+		err = en.Append(0xaa, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73)
+		if err != nil {
+			return
+		}
+		err = en.WriteArrayHeader(uint32(len(z.Categories)))
+		if err != nil {
+			err = msgp.WrapError(err, "Categories")
+			return
+		}
+		for za0001 := range z.Categories {
+			err = en.WriteString(z.Categories[za0001])
+			if err != nil {
+				err = msgp.WrapError(err, "Categories", za0001)
+				return
+			}
+		}
+	}
+	if (zb0001Mask & 0x40) == 0 { // if not empty
+		// write "previous_url"
+		err = en.Append(0xac, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x75, 0x72, 0x6c)
+		if err != nil {
+			return
+		}
+		err = en.WriteString(z.PreviousURL)
+		if err != nil {
+			err = msgp.WrapError(err, "PreviousURL")
+			return
+		}
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *Subscription) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 5
-	// string "feed_id"
-	o = append(o, 0x85, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
-	o = msgp.AppendString(o, z.FeedID)
+	// omitempty: check for empty values
+	zb0001Len := uint32(7)
+	var zb0001Mask uint8 /* 7 bits */
+	_ = zb0001Mask
+	if z.FeedID == "" {
+		zb0001Len--
+		zb0001Mask |= 0x1
+	}
+	if z.Categories == nil {
+		zb0001Len--
+		zb0001Mask |= 0x20
+	}
+	if z.PreviousURL == "" {
+		zb0001Len--
+		zb0001Mask |= 0x40
+	}
+	// variable map header, size zb0001Len
+	o = append(o, 0x80|uint8(zb0001Len))
+	if zb0001Len == 0 {
+		return
+	}
+	if (zb0001Mask & 0x1) == 0 { // if not empty
+		// string "feed_id"
+		o = append(o, 0xa7, 0x66, 0x65, 0x65, 0x64, 0x5f, 0x69, 0x64)
+		o = msgp.AppendString(o, z.FeedID)
+	}
 	// string "title"
 	o = append(o, 0xa5, 0x74, 0x69, 0x74, 0x6c, 0x65)
 	o = msgp.AppendString(o, z.Title)
@@ -1798,6 +3067,19 @@ func (z *Subscription) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "site_url"
 	o = append(o, 0xa8, 0x73, 0x69, 0x74, 0x65, 0x5f, 0x75, 0x72, 0x6c)
 	o = msgp.AppendString(o, z.SiteURL)
+	if (zb0001Mask & 0x20) == 0 { // if not empty
+		// string "categories"
+		o = append(o, 0xaa, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73)
+		o = msgp.AppendArrayHeader(o, uint32(len(z.Categories)))
+		for za0001 := range z.Categories {
+			o = msgp.AppendString(o, z.Categories[za0001])
+		}
+	}
+	if (zb0001Mask & 0x40) == 0 { // if not empty
+		// string "previous_url"
+		o = append(o, 0xac, 0x70, 0x72, 0x65, 0x76, 0x69, 0x6f, 0x75, 0x73, 0x5f, 0x75, 0x72, 0x6c)
+		o = msgp.AppendString(o, z.PreviousURL)
+	}
 	return
 }
 
@@ -1849,6 +3131,31 @@ func (z *Subscription) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "SiteURL")
 				return
 			}
+		case "categories":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Categories")
+				return
+			}
+			if cap(z.Categories) >= int(zb0002) {
+				z.Categories = (z.Categories)[:zb0002]
+			} else {
+				z.Categories = make([]string, zb0002)
+			}
+			for za0001 := range z.Categories {
+				z.Categories[za0001], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Categories", za0001)
+					return
+				}
+			}
+		case "previous_url":
+			z.PreviousURL, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "PreviousURL")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -1863,6 +3170,10 @@ func (z *Subscription) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *Subscription) Msgsize() (s int) {
-	s = 1 + 8 + msgp.StringPrefixSize + len(z.FeedID) + 6 + msgp.StringPrefixSize + len(z.Title) + 10 + msgp.StringPrefixSize + len(z.FeedType) + 9 + msgp.StringPrefixSize + len(z.FeedURL) + 9 + msgp.StringPrefixSize + len(z.SiteURL)
+	s = 1 + 8 + msgp.StringPrefixSize + len(z.FeedID) + 6 + msgp.StringPrefixSize + len(z.Title) + 10 + msgp.StringPrefixSize + len(z.FeedType) + 9 + msgp.StringPrefixSize + len(z.FeedURL) + 9 + msgp.StringPrefixSize + len(z.SiteURL) + 11 + msgp.ArrayHeaderSize
+	for za0001 := range z.Categories {
+		s += msgp.StringPrefixSize + len(z.Categories[za0001])
+	}
+	s += 13 + msgp.StringPrefixSize + len(z.PreviousURL)
 	return
 }
@@ -74,7 +74,7 @@ func TestEncodeDecodeDocument(t *testing.T) {
 
 	m := v.Msgsize()
 	if buf.Len() > m {
-		t.Logf("WARNING: Msgsize() for %v is inaccurate", v)
+		t.Log("WARNING: TestEncodeDecodeDocument Msgsize() is inaccurate")
 	}
 
 	vn := Document{}
@@ -187,7 +187,7 @@ func TestEncodeDecodeFeedItem(t *testing.T) {
 
 	m := v.Msgsize()
 	if buf.Len() > m {
-		t.Logf("WARNING: Msgsize() for %v is inaccurate", v)
+		t.Log("WARNING: TestEncodeDecodeFeedItem Msgsize() is inaccurate")
 	}
 
 	vn := FeedItem{}
@@ -300,7 +300,7 @@ func TestEncodeDecodeFeedSync(t *testing.T) {
 
 	m := v.Msgsize()
 	if buf.Len() > m {
-		t.Logf("WARNING: Msgsize() for %v is inaccurate", v)
+		t.Log("WARNING: TestEncodeDecodeFeedSync Msgsize() is inaccurate")
 	}
 
 	vn := FeedSync{}
@@ -413,7 +413,7 @@ func TestEncodeDecodeSubscription(t *testing.T) {
 
 	m := v.Msgsize()
 	if buf.Len() > m {
-		t.Logf("WARNING: Msgsize() for %v is inaccurate", v)
+		t.Log("WARNING: TestEncodeDecodeSubscription Msgsize() is inaccurate")
 	}
 
 	vn := Subscription{}
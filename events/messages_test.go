@@ -47,7 +47,8 @@ func TestSerialization(t *testing.T) {
 			ETag:         watermill.NewULID(),
 			LastModified: time.Now().Add(-2313 * time.Second).Format(time.RFC3339Nano),
 			Active:       true,
-			StatusCode:   200,
+			NotModified:  true,
+			StatusCode:   304,
 			SyncedAt:     time.Now().Truncate(time.Microsecond),
 			FeedItems:    12,
 			Title:        "Test Subscription",
@@ -92,6 +93,8 @@ func TestSerialization(t *testing.T) {
 			GUID:        watermill.NewUUID(),
 			Authors:     []string{"John E. Quincy", "Mary Anne Tester"},
 			Categories:  []string{"tests", "examples"},
+			Language:    "en-us",
+			PublishedAt: time.Now().Add(-2313 * time.Second).Truncate(time.Second),
 		}
 
 		msg, err := events.Marshal(item, watermill.NewUUID())
@@ -126,7 +129,24 @@ func TestSerialization(t *testing.T) {
 			Title:        "Thoughts on Testing Examples",
 			Description:  "A blog post about creating effective test fixtures.",
 			Encoding:     "UTF-8",
+			Charset:      "windows-1252",
 			Link:         "https://example.com/blog/testing-examples.html",
+
+			Text:    "Thoughts on Testing Examples\n\nA blog post about creating effective test fixtures.",
+			Article: "<div><h1>Thoughts on Testing Examples</h1><p>A blog post about creating effective test fixtures.</p></div>",
+
+			DetectedLanguage:   "en",
+			LanguageConfidence: 0.82,
+
+			Canonical:   "https://example.com/blog/testing-examples.html",
+			SiteName:    "Example Blog",
+			Author:      "Jane Doe",
+			Keywords:    []string{"testing", "fixtures"},
+			PublishedAt: time.Date(2023, time.July, 5, 9, 30, 0, 0, time.Local),
+			ModifiedAt:  time.Date(2023, time.July, 6, 14, 0, 0, 0, time.Local),
+			OpenGraph:   map[string]string{"og:title": "Thoughts on Testing Examples", "og:type": "article"},
+			TwitterCard: map[string]string{"twitter:card": "summary"},
+			LinkedData:  []byte(`{"@type":"BlogPosting","headline":"Thoughts on Testing Examples"}`),
 		}
 
 		msg, err := events.Marshal(doc, watermill.NewUUID())
@@ -0,0 +1,14 @@
+package baleen
+
+import "github.com/ThreeDotsLabs/watermill/message"
+
+// SyncFeed synchronizes the feed outside of the feed sync for the tests. If published is
+// true the items are recorded in the seen-item index as the feed sync does once the
+// events have been published, otherwise they are returned again by the next sync.
+func SyncFeed(feed *Feed, published bool) (msgs []*message.Message, err error) {
+	var seen *seenItems
+	if msgs, seen, err = feed.syncItems(); err == nil && published {
+		feed.markSeen(seen)
+	}
+	return msgs, err
+}
@@ -0,0 +1,282 @@
+package fetch
+
+import (
+	"bytes"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Article is the main content of an HTML document with the boilerplate (navigation,
+// ads, footers, scripts, etc.) removed, as both a cleaned HTML fragment and plain text.
+type Article struct {
+	HTML string // a cleaned HTML fragment containing only the main content
+	Text string // the plain text of the main content with paragraphs separated by blank lines
+}
+
+// Elements that never contain the main content of a document.
+const boilerplate = "script, style, noscript, template, iframe, svg, canvas, object, embed, form, button, input, select, textarea, nav, header, footer, aside, menu, dialog"
+
+// Elements whose text is scored as a paragraph of content.
+const paragraphs = "p, pre, td, blockquote"
+
+// Block elements that separate the paragraphs of the text of the article.
+const blocks = "p, pre, blockquote, li, h1, h2, h3, h4, h5, h6, figcaption, dt, dd"
+
+var (
+	unlikelyCandidates = regexp.MustCompile(`(?i)ad-|ads|advert|banner|breadcrumb|combx|comment|community|cookie|disqus|extra|foot|header|legends|menu|modal|nav|newsletter|pager|pagination|popup|promo|related|remark|rss|share|shoutbox|sidebar|skyscraper|social|sponsor|subscribe|tweet|twitter|widget`)
+	likelyCandidates   = regexp.MustCompile(`(?i)and|article|body|column|content|main|shadow`)
+	positiveWeight     = regexp.MustCompile(`(?i)article|body|content|entry|hentry|h-entry|main|page|post|text|blog|story`)
+	negativeWeight     = regexp.MustCompile(`(?i)-ad-|hidden|^hid$| hid$| hid |^hid |banner|combx|comment|com-|contact|foot|footer|footnote|masthead|media|meta|outbrain|promo|related|scroll|share|shoutbox|sidebar|skyscraper|sponsor|shopping|tags|tool|widget`)
+)
+
+// Attributes kept on the elements of the cleaned HTML fragment.
+var keepAttrs = map[string]struct{}{
+	"href":  {},
+	"src":   {},
+	"alt":   {},
+	"title": {},
+}
+
+// Article extracts the main content of the document using a readability-style
+// heuristic: boilerplate elements are removed, the remaining paragraphs are scored by
+// their length and punctuation, and the scores are propagated to their ancestors. The
+// highest scoring element, along with any siblings that score well, is the article.
+// An empty article is returned if the document cannot be parsed.
+func (h *HTML) Article() Article {
+	if h.article == nil {
+		h.article = &Article{}
+		if err := h.decode(); err == nil {
+			if tree, err := goquery.NewDocumentFromReader(bytes.NewReader(h.decoded)); err == nil {
+				*h.article = extractArticle(tree)
+			}
+		}
+	}
+	return *h.article
+}
+
+func extractArticle(tree *goquery.Document) Article {
+	body := tree.Find("body")
+	if body.Length() == 0 {
+		body = tree.Selection
+	}
+
+	// Remove the elements that are unlikely to be part of the main content.
+	body.Find(boilerplate).Remove()
+	body.Find("*").Each(func(_ int, s *goquery.Selection) {
+		if s.Is("html, body, article, main") {
+			return
+		}
+
+		match := s.AttrOr("class", "") + " " + s.AttrOr("id", "") + " " + s.AttrOr("role", "")
+		if unlikelyCandidates.MatchString(match) && !likelyCandidates.MatchString(match) {
+			s.Remove()
+		}
+	})
+
+	// Score the paragraphs and propagate the scores to their parent and grandparent.
+	scores := make(map[*html.Node]float64)
+	candidates := make([]*goquery.Selection, 0)
+	body.Find(paragraphs).Each(func(_ int, p *goquery.Selection) {
+		text := normalizeSpace(p.Text())
+		if len([]rune(text)) < 25 {
+			return
+		}
+
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len([]rune(text)))/100, 3)
+		for level, ancestor := range []*goquery.Selection{p.Parent(), p.Parent().Parent()} {
+			if ancestor.Length() == 0 {
+				continue
+			}
+
+			node := ancestor.Get(0)
+			if _, ok := scores[node]; !ok {
+				scores[node] = classWeight(ancestor) + tagWeight(ancestor)
+				candidates = append(candidates, ancestor)
+			}
+
+			if level == 0 {
+				scores[node] += score
+			} else {
+				scores[node] += score / 2
+			}
+		}
+	})
+
+	// Select the candidate with the highest score, discounted by its link density.
+	var top *goquery.Selection
+	var topScore float64
+	for _, candidate := range candidates {
+		node := candidate.Get(0)
+		scores[node] *= 1 - linkDensity(candidate)
+		if top == nil || scores[node] > topScore {
+			top, topScore = candidate, scores[node]
+		}
+	}
+
+	if top == nil {
+		top = body
+	}
+
+	// Include the siblings of the top candidate that are likely to be part of the
+	// article, e.g. paragraphs that are split across multiple containers.
+	threshold := math.Max(10, topScore*0.2)
+	article := top
+	if parent := top.Parent(); parent.Length() > 0 && top.Get(0) != body.Get(0) {
+		article = parent.Children().FilterFunction(func(_ int, sibling *goquery.Selection) bool {
+			if sibling.Get(0) == top.Get(0) {
+				return true
+			}
+
+			if score, ok := scores[sibling.Get(0)]; ok && score >= threshold {
+				return true
+			}
+
+			if sibling.Is("p") {
+				text := normalizeSpace(sibling.Text())
+				density := linkDensity(sibling)
+				return (len(text) > 80 && density < 0.25) || (len(text) > 0 && density == 0 && strings.ContainsAny(text, ".!?"))
+			}
+			return false
+		})
+	}
+
+	return Article{
+		HTML: cleanFragment(article),
+		Text: articleText(article),
+	}
+}
+
+// Returns the HTML of the selection wrapped in a div with all attributes removed
+// except for those needed to preserve links and images, and without empty elements.
+func cleanFragment(article *goquery.Selection) string {
+	var buf strings.Builder
+	buf.WriteString("<div>")
+	article.Each(func(_ int, s *goquery.Selection) {
+		s = s.Clone()
+		s.Find("*").AddSelection(s).Each(func(_ int, e *goquery.Selection) {
+			node := e.Get(0)
+			attrs := node.Attr[:0]
+			for _, attr := range node.Attr {
+				if _, ok := keepAttrs[attr.Key]; ok {
+					attrs = append(attrs, attr)
+				}
+			}
+			node.Attr = attrs
+		})
+
+		s.Find("*").Each(func(_ int, e *goquery.Selection) {
+			if e.Is("img, br, hr") || e.Find("img").Length() > 0 {
+				return
+			}
+
+			if normalizeSpace(e.Text()) == "" {
+				e.Remove()
+			}
+		})
+
+		s.Find("*").AddSelection(s).Contents().Each(func(_ int, c *goquery.Selection) {
+			if c.Get(0).Type == html.CommentNode {
+				c.Remove()
+			}
+		})
+
+		// The body itself is not part of the fragment, only its content
+		render := goquery.OuterHtml
+		if s.Is("body") {
+			render = (*goquery.Selection).Html
+		}
+
+		if fragment, err := render(s); err == nil {
+			buf.WriteString(strings.TrimSpace(fragment))
+		}
+	})
+	buf.WriteString("</div>")
+	return buf.String()
+}
+
+// Returns the text of the article with the text of each block separated by blank lines.
+func articleText(article *goquery.Selection) string {
+	texts := make([]string, 0)
+	article.Each(func(_ int, s *goquery.Selection) {
+		if s.Is(blocks) {
+			if text := normalizeSpace(s.Text()); text != "" {
+				texts = append(texts, text)
+			}
+			return
+		}
+
+		s.Find(blocks).Each(func(_ int, block *goquery.Selection) {
+			// Nested blocks are included in the text of their outermost block
+			if block.ParentsUntilSelection(s).Filter(blocks).Length() > 0 {
+				return
+			}
+
+			if text := normalizeSpace(block.Text()); text != "" {
+				texts = append(texts, text)
+			}
+		})
+	})
+
+	if len(texts) == 0 {
+		return normalizeSpace(article.Text())
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+// Returns the fraction of the text of the selection that is the text of links.
+func linkDensity(s *goquery.Selection) float64 {
+	length := len(normalizeSpace(s.Text()))
+	if length == 0 {
+		return 0
+	}
+
+	var links int
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		links += len(normalizeSpace(a.Text()))
+	})
+	return float64(links) / float64(length)
+}
+
+// Weights a candidate by its class and id, favoring names commonly used for content.
+func classWeight(s *goquery.Selection) (weight float64) {
+	for _, name := range []string{s.AttrOr("class", ""), s.AttrOr("id", "")} {
+		if name == "" {
+			continue
+		}
+
+		if negativeWeight.MatchString(name) {
+			weight -= 25
+		}
+
+		if positiveWeight.MatchString(name) {
+			weight += 25
+		}
+	}
+	return weight
+}
+
+// Weights a candidate by its tag, favoring elements that usually contain content.
+func tagWeight(s *goquery.Selection) float64 {
+	switch {
+	case s.Is("article, main"):
+		return 10
+	case s.Is("div"):
+		return 5
+	case s.Is("pre, td, blockquote"):
+		return 3
+	case s.Is("ol, ul, dl, dd, dt, li, form"):
+		return -3
+	case s.Is("h1, h2, h3, h4, h5, h6, th"):
+		return -5
+	default:
+		return 0
+	}
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
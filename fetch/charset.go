@@ -0,0 +1,154 @@
+package fetch
+
+import (
+	"bytes"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rotationalio/baleen/langid"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+)
+
+// The number of bytes at the start of the document that are checked for a declared
+// character set, following the prescan algorithm of the HTML specification.
+const prescanBytes = 1024
+
+// How much better a later candidate must fit than an earlier one to be preferred when
+// sniffing, since permissive multi-byte character sets can decode other character sets
+// into plausible looking text.
+const sniffMargin = 0.1
+
+// Character sets that are tried when a document does not declare its character set and
+// is not valid UTF-8, along with the languages that are expected to be written in each
+// character set. If no languages are specified, any language may be written in it. The
+// order matters: permissive character sets that can decode almost any bytes come last.
+var sniffCharsets = []struct {
+	name      string
+	languages []string
+}{
+	{"shift_jis", []string{"ja"}},
+	{"euc-jp", []string{"ja"}},
+	{"euc-kr", []string{"ko"}},
+	{"gb18030", []string{"zh"}},
+	{"windows-1251", []string{"ru", "uk"}},
+	{"koi8-r", []string{"ru", "uk"}},
+	{"windows-1252", nil},
+}
+
+var (
+	metaCharset = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([a-z0-9_:.\-]+)`)
+	markup      = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]*>`)
+
+	// Character set declarations of meta tags and the XML declaration with the name of
+	// the character set as the second group so that they can be rewritten.
+	charsetDeclarations = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)(<meta[^>]+charset\s*=\s*["']?\s*)([a-z0-9_:.\-]+)`),
+		regexp.MustCompile(`(?i)(<\?xml[^>]+encoding\s*=\s*["']\s*)([a-z0-9_:.\-]+)`),
+	}
+)
+
+// Detects the character set of the content, returning the encoding and its canonical
+// name. The character set is determined by the byte order mark, the charset parameter
+// of the Content-Type header, or a meta tag, in that order. If the content does not
+// declare a character set and is not valid UTF-8, the character set is sniffed by
+// decoding the content with common character sets and identifying the language.
+func detectCharset(content []byte, contentType string) (encoding.Encoding, string) {
+	enc, name, certain := charset.DetermineEncoding(content, contentType)
+	if certain {
+		return enc, name
+	}
+
+	head := content
+	if len(head) > prescanBytes {
+		head = head[:prescanBytes]
+	}
+
+	if match := metaCharset.FindSubmatch(head); match != nil {
+		if enc, name = charset.Lookup(string(match[1])); enc != nil {
+			return enc, name
+		}
+	}
+
+	if utf8.Valid(content) {
+		return encoding.Nop, "utf-8"
+	}
+	return sniffCharset(content)
+}
+
+// Rewrites the character set declared by the meta tags and XML declaration of content
+// that has been transcoded to UTF-8 so that the document is not decoded with its
+// original character set by consumers of the transcoded content.
+func declareUTF8(content []byte) []byte {
+	for _, declaration := range charsetDeclarations {
+		content = declaration.ReplaceAll(content, []byte("${1}utf-8"))
+	}
+	return content
+}
+
+// Decodes the content with each of the candidate character sets and returns the one
+// whose text is identified with the most confidence as a language expected for the
+// character set. Candidates that cannot decode the content are skipped and the first
+// candidate is preferred unless another fits better by more than the sniff margin.
+func sniffCharset(content []byte) (encoding.Encoding, string) {
+	best, bestName, bestScore := encoding.Encoding(nil), "", 0.0
+	for _, candidate := range sniffCharsets {
+		enc, name := charset.Lookup(candidate.name)
+		if enc == nil {
+			continue
+		}
+
+		decoded, err := enc.NewDecoder().Bytes(content)
+		if err != nil || bytes.ContainsRune(decoded, utf8.RuneError) {
+			continue
+		}
+
+		text := markup.ReplaceAll(decoded, []byte(" "))
+		code, confidence := langid.Detect(string(text))
+		if len(candidate.languages) > 0 && !contains(candidate.languages, code) {
+			continue
+		}
+
+		// Earlier candidates are preferred unless a later candidate is a better fit.
+		if score := confidence * plausibility(text); best == nil || score > bestScore+sniffMargin {
+			best, bestName, bestScore = enc, name, score
+		}
+	}
+
+	if best == nil {
+		return encoding.Nop, "utf-8"
+	}
+	return best, bestName
+}
+
+// Returns the fraction of the letters in the text that are not half-width katakana,
+// which rarely appear in text but do appear when content is decoded with the wrong
+// multi-byte character set.
+func plausibility(text []byte) float64 {
+	var letters, implausible int
+	for _, r := range string(text) {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		letters++
+		if r >= 0xFF61 && r <= 0xFF9F {
+			implausible++
+		}
+	}
+
+	if letters == 0 {
+		return 0
+	}
+	return 1 - float64(implausible)/float64(letters)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
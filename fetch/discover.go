@@ -0,0 +1,114 @@
+package fetch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+)
+
+// The media types of the feeds that are discovered from the alternate links of a page.
+var feedTypes = map[string]struct{}{
+	"application/rss+xml":   {},
+	"application/atom+xml":  {},
+	"application/rdf+xml":   {},
+	"application/feed+json": {},
+}
+
+// Common paths that are probed for a feed if a page does not link to any feeds.
+var feedPaths = []string{"/feed", "/rss", "/feed.xml", "/rss.xml", "/atom.xml", "/index.xml", "/feed.json"}
+
+// Only the head of a page is needed to discover its feeds, so large pages are truncated.
+const maxDiscoverSize = 2 * 1024 * 1024
+
+// Returns true if the content type of the response is a web page rather than a feed.
+func isHTML(ctype string) bool {
+	mediatype, _, err := mime.ParseMediaType(ctype)
+	if err != nil {
+		return false
+	}
+	return mediatype == "text/html" || mediatype == "application/xhtml+xml"
+}
+
+// Returns true if the body of a response is a JSON, RSS, or Atom feed regardless of the
+// content type of the response.
+func isFeed(ctype string, body []byte) bool {
+	return isJSONFeed(ctype, body) || gofeed.DetectFeedType(bytes.NewReader(body)) != gofeed.FeedTypeUnknown
+}
+
+// Discovers the feeds of a web page from its alternate links, falling back to probing
+// the common feed paths of the site if the page does not link to any feeds.
+func discover(ctx context.Context, base *url.URL, body []byte) NotFeedError {
+	err := NotFeedError{URL: base.String()}
+
+	if len(body) > maxDiscoverSize {
+		body = body[:maxDiscoverSize]
+	}
+
+	if tree, perr := goquery.NewDocumentFromReader(bytes.NewReader(body)); perr == nil {
+		err.Feeds = alternateFeeds(tree, base)
+	}
+
+	if len(err.Feeds) == 0 {
+		err.Feeds = probeFeeds(ctx, base)
+	}
+	return err
+}
+
+// Returns the absolute urls of the feeds linked to by <link rel="alternate"> elements,
+// resolved relative to the <base> of the page. Comment feeds are listed last since the
+// main feed of the site is preferred.
+func alternateFeeds(tree *goquery.Document, base *url.URL) []string {
+	if href, ok := tree.Find("base[href]").Attr("href"); ok {
+		if u, err := base.Parse(strings.TrimSpace(href)); err == nil {
+			base = u
+		}
+	}
+
+	var feeds, comments []string
+	tree.Find(`link[rel~="alternate"][href]`).Each(func(_ int, link *goquery.Selection) {
+		ctype := strings.ToLower(strings.TrimSpace(link.AttrOr("type", "")))
+		if _, ok := feedTypes[ctype]; !ok {
+			return
+		}
+
+		u, err := base.Parse(strings.TrimSpace(link.AttrOr("href", "")))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return
+		}
+
+		if strings.Contains(strings.ToLower(link.AttrOr("title", "")), "comments") {
+			comments = appendUnique(comments, u.String())
+			return
+		}
+		feeds = appendUnique(feeds, u.String())
+	})
+
+	for _, feed := range comments {
+		feeds = appendUnique(feeds, feed)
+	}
+	return feeds
+}
+
+// Probes the common feed paths of the site and returns the url of the first path that
+// responds with a feed. Probed urls are not themselves used to discover feeds.
+func probeFeeds(ctx context.Context, base *url.URL) []string {
+	for _, path := range feedPaths {
+		probe := NewFeedFetcher(base.ResolveReference(&url.URL{Path: path}).String())
+		probe.discover = false
+
+		if _, err := probe.fetch(ctx); err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil
+			}
+			continue
+		}
+		return []string{probe.URL()}
+	}
+	return nil
+}
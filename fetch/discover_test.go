@@ -0,0 +1,110 @@
+package fetch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/rotationalio/baleen/fetch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverAlternateFeeds(t *testing.T) {
+	page := `<html><head>
+		<title>Example Site</title>
+		<link rel="alternate" type="application/rss+xml" title="Comments Feed" href="/comments/feed" />
+		<link rel="alternate" type="application/rss+xml" title="Example Feed" href="/feed.xml" />
+		<link rel="alternate" type="application/atom+xml" href="https://feeds.example.com/atom" />
+		<link rel="alternate" type="text/html" hreflang="fr" href="/fr/" />
+		<link rel="stylesheet" type="text/css" href="/style.css" />
+	</head><body><p>Hello world</p></body></html>`
+
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(rw, page)
+	})
+
+	fetcher := fetch.NewFeedFetcher(url + "/blog/")
+	feed, err := fetcher.Fetch(context.Background())
+	require.Error(t, err)
+	require.Nil(t, feed)
+
+	var notFeed fetch.NotFeedError
+	require.True(t, errors.As(err, &notFeed), "expected a not feed error to be returned")
+	require.Equal(t, url+"/blog/", notFeed.URL)
+	require.Equal(t, []string{url + "/feed.xml", "https://feeds.example.com/atom", url + "/comments/feed"}, notFeed.Feeds, "comment feeds should be discovered last")
+	require.False(t, fetch.Retryable(err), "not feed errors should not be retried")
+}
+
+func TestFeedServedAsHTML(t *testing.T) {
+	// Many servers send feeds with a text/html content type so the body is parsed
+	// rather than discovering feeds from it as though it were a web page.
+	for _, fixture := range []string{"testdata/rss2.xml", "testdata/atom1.xml", "testdata/json11.json"} {
+		data, err := os.ReadFile(fixture)
+		require.NoError(t, err)
+
+		url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+			rw.Write(data)
+		})
+
+		feed, err := fetch.NewFeedFetcher(url + "/news.xml").Fetch(context.Background())
+		require.NoError(t, err, "could not fetch %s served as text/html", fixture)
+		require.NotEmpty(t, feed.Items, "expected items in %s", fixture)
+	}
+}
+
+func TestDiscoverBaseHref(t *testing.T) {
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(rw, `<html><head><base href="/site/"><link rel="alternate" type="application/atom+xml" href="atom.xml"></head></html>`)
+	})
+
+	_, err := fetch.NewFeedFetcher(url).Fetch(context.Background())
+	var notFeed fetch.NotFeedError
+	require.True(t, errors.As(err, &notFeed), "expected a not feed error to be returned")
+	require.Equal(t, []string{url + "/site/atom.xml"}, notFeed.Feeds)
+}
+
+func TestDiscoverCommonPaths(t *testing.T) {
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/":
+			rw.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(rw, `<html><head><title>No Feeds</title></head></html>`)
+		case "/feed":
+			// Some sites serve a web page at the feed path which should not be discovered
+			rw.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(rw, `<html><head><link rel="alternate" type="application/rss+xml" href="/other.xml"></head></html>`)
+		case "/rss.xml":
+			FixtureHandler(t, "testdata/rss2.xml")(rw, req)
+		default:
+			http.NotFound(rw, req)
+		}
+	})
+
+	_, err := fetch.NewFeedFetcher(url).Fetch(context.Background())
+	var notFeed fetch.NotFeedError
+	require.True(t, errors.As(err, &notFeed), "expected a not feed error to be returned")
+	require.Equal(t, []string{url + "/rss.xml"}, notFeed.Feeds)
+}
+
+func TestDiscoverNoFeeds(t *testing.T) {
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/" {
+			http.NotFound(rw, req)
+			return
+		}
+		rw.Header().Set("Content-Type", "application/xhtml+xml")
+		fmt.Fprint(rw, `<html><head><title>No Feeds</title></head></html>`)
+	})
+
+	_, err := fetch.NewFeedFetcher(url).Fetch(context.Background())
+	var notFeed fetch.NotFeedError
+	require.True(t, errors.As(err, &notFeed), "expected a not feed error to be returned")
+	require.Empty(t, notFeed.Feeds)
+	require.Contains(t, err.Error(), "no feeds were discovered")
+}
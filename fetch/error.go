@@ -1,17 +1,33 @@
 package fetch
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 )
 
+// ErrDisallowed is returned by the fetchers when the robots.txt of the host does not
+// allow Baleen to fetch the url; no request is made for the url.
+var ErrDisallowed = errors.New("url is disallowed by robots.txt")
+
 // HTTPError contains status information from the request and can be returned as error.
 // This type of error is returned from the Fetcher when the server replies successfully
 // but without a 200 status. The suggested use of this error is in a switch statement,
 // e.g. something like: switch he := err.(type) {case fetch.HTTPError: ... default: ...}
 type HTTPError struct {
-	Code   int
-	Status string
+	Code       int
+	Status     string
+	RetryAfter time.Duration // the delay requested by the Retry-After header, if any
+}
+
+// Creates an HTTPError from a response that does not have a 2xx status.
+func newHTTPError(rep *http.Response) HTTPError {
+	return HTTPError{
+		Status:     rep.Status,
+		Code:       rep.StatusCode,
+		RetryAfter: retryAfter(rep.Header),
+	}
 }
 
 // Error implements the error interface and returns a string representation of the err.
@@ -33,3 +49,33 @@ func (e HTTPError) Forbidden() bool {
 func (e HTTPError) NotFound() bool {
 	return e.Code == http.StatusNotFound
 }
+
+// Temporary returns true if the error is an HTTP 408, 425, 429, 500, 502, 503 or 504,
+// which indicate that the request may succeed if it is retried later.
+func (e HTTPError) Temporary() bool {
+	switch e.Code {
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// NotFeedError is returned by the FeedFetcher when the url responds with a web page
+// rather than a feed, e.g. when the url of a site was subscribed to instead of the url of
+// its feed. Feeds contains the urls of the feeds discovered from the page, ordered by
+// preference, and is empty if no feeds could be discovered.
+type NotFeedError struct {
+	URL   string
+	Feeds []string
+}
+
+// Error implements the error interface and returns a string representation of the err.
+func (e NotFeedError) Error() string {
+	if len(e.Feeds) == 0 {
+		return fmt.Sprintf("%s is not a feed and no feeds were discovered", e.URL)
+	}
+	return fmt.Sprintf("%s is not a feed, discovered %d feed(s)", e.URL, len(e.Feeds))
+}
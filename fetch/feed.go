@@ -1,10 +1,16 @@
 package fetch
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/rss"
 )
 
 // FeedFetcher provides a interface for anything that can get RSS data and provide it in
@@ -17,25 +23,50 @@ type FeedFetcher struct {
 	parser   *gofeed.Parser // the universal feed parser for RSS and Atom feeds
 	etag     string         // used for conditional http to minimize bandwidth
 	modified string         // used for conditional http to minimize bandwidth
+	expires  time.Time      // when the response expires according to cache headers
+	size     int64          // the number of bytes read from the last response body
+	moved    string         // the url the feed permanently moved to on the last request
+	discover bool           // discover the feeds of web pages rather than failing to parse
 }
 
 // NewFeedFetcher creates a new HTTP fetcher that can fetch rss feeds from the specified URL.
 func NewFeedFetcher(url string) *FeedFetcher {
+	parser := gofeed.NewParser()
+	parser.RSSTranslator = &rssTranslator{}
+
 	return &FeedFetcher{
-		url:    url,
-		parser: gofeed.NewParser(),
+		url:      url,
+		parser:   parser,
+		discover: true,
 	}
 }
 
 // The FeedFetcher uses GET requests to retrieve data with a Baleen-specific http
 // client. We avoid using gofeed.ParseURL because it is very simple and doesn't respect
 // rate limits or etags, which are necessary for Baleen to run in continuous operation.
+// Transient failures are retried according to the package retry policy.
 func (f *FeedFetcher) Fetch(ctx context.Context) (feed *gofeed.Feed, err error) {
+	err = retryPolicy.do(ctx, func() error {
+		feed, err = f.fetch(ctx)
+		return err
+	})
+	return feed, err
+}
+
+func (f *FeedFetcher) fetch(ctx context.Context) (feed *gofeed.Feed, err error) {
+	f.moved = ""
+
 	var req *http.Request
 	if req, err = f.newRequest(ctx); err != nil {
 		return nil, err
 	}
 
+	// Do not fetch the url if it is disallowed by the robots.txt of the host and wait
+	// until the request is allowed by the rate limit and Crawl-delay of the host.
+	if err = polite(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
 	var rep *http.Response
 	if rep, err = client.Do(req); err != nil {
 		return nil, err
@@ -46,19 +77,53 @@ func (f *FeedFetcher) Fetch(ctx context.Context) (feed *gofeed.Feed, err error)
 		defer rep.Body.Close()
 	}
 
+	// Cache headers are returned with both 200 and 304 responses.
+	f.expires = expires(rep.Header)
+	f.size = 0
+
+	// If the feed was permanently redirected to a url that responded successfully,
+	// request the new url from now on; temporary redirects are followed every time.
+	if rep.StatusCode == http.StatusNotModified || (rep.StatusCode >= 200 && rep.StatusCode < 300) {
+		if moved := permanentRedirect(rep); moved != "" && moved != f.url {
+			f.url, f.moved = moved, moved
+		}
+	}
+
 	// Check the status code of the response; note that 304 means not modified, but we
 	// are still returning a 304 error to signal to the Subscription that nothing has
 	// changed and that the feed is nil.
 	if rep.StatusCode < 200 || rep.StatusCode >= 300 {
-		return nil, HTTPError{
-			Status: rep.Status,
-			Code:   rep.StatusCode,
+		return nil, newHTTPError(rep)
+	}
+
+	// Read the body so that the type of feed can be detected before it is parsed
+	body := &countingReader{r: rep.Body}
+	data, err := io.ReadAll(body)
+	f.size = body.n
+	if err != nil {
+		return nil, err
+	}
+
+	// If the url is a web page rather than a feed, discover the feeds that it links to.
+	// Many servers send feeds as text/html so the body is checked before discovery.
+	ctype := rep.Header.Get(HeaderContentType)
+	if isHTML(ctype) && !isFeed(ctype, data) {
+		if f.discover {
+			return nil, discover(ctx, rep.Request.URL, data)
 		}
+		return nil, NotFeedError{URL: rep.Request.URL.String()}
 	}
 
-	// Use the universal parser to parse the Atom or RSS feed
+	// JSON Feeds are parsed directly, otherwise use the universal parser to parse the
+	// Atom or RSS feed.
 	// Note: Feeds with illegal character codes will not be successfully parsed & return nil here
-	if feed, err = f.parser.Parse(rep.Body); err != nil {
+	if isJSONFeed(ctype, data) {
+		feed, err = parseJSONFeed(data)
+	} else {
+		feed, err = f.parser.Parse(bytes.NewReader(data))
+	}
+
+	if err != nil {
 		return nil, err
 	}
 
@@ -80,6 +145,36 @@ func (f *FeedFetcher) Modified() string {
 	return f.modified
 }
 
+// Expires returns the time that the last response expires according to its
+// Cache-Control or Expires headers, or the zero time if no cache headers were sent.
+func (f *FeedFetcher) Expires() time.Time {
+	return f.expires
+}
+
+// Size returns the number of bytes read from the body of the last response.
+func (f *FeedFetcher) Size() int64 {
+	return f.size
+}
+
+// Moved returns the url the feed was permanently redirected to (301 or 308) by the
+// last request, or an empty string if the feed has not moved. The fetcher requests the
+// new url from then on, so callers should update any references to the old url.
+func (f *FeedFetcher) Moved() string {
+	return f.moved
+}
+
+// URL returns the url of the feed that is currently being fetched.
+func (f *FeedFetcher) URL() string {
+	return f.url
+}
+
+// Restore the conditional http state of the fetcher from a previous session, e.g. when
+// a feed is reloaded from a persisted manifest, so that unchanged feeds are not refetched.
+func (f *FeedFetcher) Restore(etag, modified string) {
+	f.etag = etag
+	f.modified = modified
+}
+
 func (f *FeedFetcher) newRequest(ctx context.Context) (req *http.Request, err error) {
 	// Create the GET request
 	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil); err != nil {
@@ -116,3 +211,123 @@ func (f *FeedFetcher) newRequest(ctx context.Context) (req *http.Request, err er
 	req.Header.Set(HeaderRFC3229, aimType)
 	return req, nil
 }
+
+// TTL returns the minimum time that the feed specifies it should be cached before it is
+// refreshed, using either the RSS <ttl> element or the syndication module's
+// sy:updatePeriod and sy:updateFrequency elements. Zero is returned if not specified.
+func TTL(feed *gofeed.Feed) time.Duration {
+	if feed == nil {
+		return 0
+	}
+
+	if ttl, err := strconv.Atoi(strings.TrimSpace(feed.Custom[customTTL])); err == nil && ttl > 0 {
+		return time.Duration(ttl) * time.Minute
+	}
+
+	if sy, ok := feed.Extensions["sy"]; ok {
+		var period time.Duration
+		if exts := sy["updatePeriod"]; len(exts) > 0 {
+			switch strings.ToLower(strings.TrimSpace(exts[0].Value)) {
+			case "hourly":
+				period = time.Hour
+			case "daily":
+				period = 24 * time.Hour
+			case "weekly":
+				period = 7 * 24 * time.Hour
+			case "monthly":
+				period = 30 * 24 * time.Hour
+			case "yearly":
+				period = 365 * 24 * time.Hour
+			}
+		}
+
+		frequency := 1
+		if exts := sy["updateFrequency"]; len(exts) > 0 {
+			if freq, err := strconv.Atoi(strings.TrimSpace(exts[0].Value)); err == nil && freq > 0 {
+				frequency = freq
+			}
+		}
+
+		if period > 0 {
+			return period / time.Duration(frequency)
+		}
+	}
+	return 0
+}
+
+// The key in the gofeed.Feed Custom map used to preserve the RSS <ttl> element, which
+// is otherwise dropped by the universal feed translator.
+const customTTL = "ttl"
+
+// rssTranslator extends the default gofeed RSS translator to preserve the <ttl>.
+type rssTranslator struct {
+	gofeed.DefaultRSSTranslator
+}
+
+func (t *rssTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	result, err := t.DefaultRSSTranslator.Translate(feed)
+	if err != nil {
+		return nil, err
+	}
+
+	if src, ok := feed.(*rss.Feed); ok && src.TTL != "" {
+		if result.Custom == nil {
+			result.Custom = make(map[string]string)
+		}
+		result.Custom[customTTL] = src.TTL
+	}
+	return result, nil
+}
+
+// Computes when a response expires from its Cache-Control max-age directive, falling
+// back to the Expires header. Returns the zero time if neither header is usable.
+func expires(header http.Header) time.Time {
+	now := time.Now()
+	for _, directive := range strings.Split(header.Get(HeaderCacheControl), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if strings.HasPrefix(directive, "max-age=") {
+			if age, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && age > 0 {
+				return now.Add(time.Duration(age) * time.Second)
+			}
+		}
+	}
+
+	if exp := header.Get(HeaderExpires); exp != "" {
+		if ts, err := http.ParseTime(exp); err == nil && ts.After(now) {
+			return ts
+		}
+	}
+	return time.Time{}
+}
+
+// Returns the url that the response was permanently redirected to by following the
+// redirects of the request from its original url, or an empty string if the request
+// was not redirected. Only the leading permanent redirects (301 or 308) are followed;
+// the feed has not moved to urls that are reached via a temporary redirect.
+func permanentRedirect(rep *http.Response) (moved string) {
+	// Each redirected request references the redirect response that created it, so the
+	// chain is walked backwards from the final request to the original request.
+	for req := rep.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		switch req.Response.StatusCode {
+		case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+			if moved == "" {
+				moved = req.URL.String()
+			}
+		default:
+			moved = ""
+		}
+	}
+	return moved
+}
+
+// Counts the number of bytes read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (n int, err error) {
+	n, err = c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
 	"github.com/rotationalio/baleen/fetch"
 	"github.com/stretchr/testify/require"
 )
@@ -20,6 +23,7 @@ func TestRSSResponse(t *testing.T) {
 	require.Equal(t, feed.FeedType, "rss")
 	require.Equal(t, feed.Title, "Sample Feed")
 	require.Equal(t, len(feed.Items), 1)
+	require.Greater(t, fetcher.Size(), int64(0), "expected the response size to be recorded")
 }
 
 func TestAtomResponse(t *testing.T) {
@@ -35,6 +39,84 @@ func TestAtomResponse(t *testing.T) {
 	require.Equal(t, len(feed.Items), 1)
 }
 
+func TestJSONFeedResponse(t *testing.T) {
+	// Create a test server serving JSON Feed 1.0 data
+	url := NewServer(t, FixtureHandler(t, "testdata/json1.json"))
+
+	fetcher := fetch.NewFeedFetcher(url)
+	feed, err := fetcher.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "json", feed.FeedType)
+	require.Equal(t, "1.0", feed.FeedVersion)
+	require.Equal(t, "Sample Feed", feed.Title)
+	require.Equal(t, "http://example.org/", feed.Link)
+	require.Equal(t, "http://example.org/feed.json", feed.FeedLink)
+	require.Equal(t, "http://example.org/icon.png", feed.Image.URL)
+	require.Len(t, feed.Items, 2)
+	require.Equal(t, "2005-11-10T09:30:00-05:00", feed.Published, "expected the feed to be dated by its latest item")
+
+	// Numeric ids should be coerced to strings and plain text content preserved
+	item := feed.Items[0]
+	require.Equal(t, "2", item.GUID)
+	require.Equal(t, "This is the second item.", item.Content)
+	require.Equal(t, "This is the second item.", fetch.ContentText(item))
+	require.Equal(t, "Mark Pilgrim", item.Authors[0].Name, "items should be authored by the feed author")
+	require.NotNil(t, item.PublishedParsed)
+
+	item = feed.Items[1]
+	require.Equal(t, "http://example.org/item/1", item.GUID)
+	require.Equal(t, "<p>This is the <em>first</em> item.</p>", item.Content)
+	require.Empty(t, fetch.ContentText(item))
+	require.Equal(t, "Watch out for nasty tricks", item.Description)
+	require.Equal(t, []string{"Test", "Sample"}, item.Categories)
+	require.True(t, item.UpdatedParsed.After(*item.PublishedParsed))
+}
+
+func TestJSONFeed11Response(t *testing.T) {
+	// JSON Feeds are detected from their content when served as text/plain
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		FixtureHandler(t, "testdata/json11.json")(rw, req)
+	})
+
+	fetcher := fetch.NewFeedFetcher(url)
+	feed, err := fetcher.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "json", feed.FeedType)
+	require.Equal(t, "1.1", feed.FeedVersion)
+	require.Equal(t, "en-US", feed.Language)
+	require.Len(t, feed.Authors, 2)
+	require.Equal(t, "http://example.org/john", feed.Authors[1].Name, "authors with only a url should be named by it")
+	require.Len(t, feed.Items, 2)
+
+	item := feed.Items[0]
+	require.Equal(t, "episode-1", item.GUID)
+	require.Equal(t, "<p>The <strong>first</strong> episode.</p>", item.Content)
+	require.Equal(t, "The first episode.", fetch.ContentText(item))
+	require.Equal(t, "fr", fetch.ItemLanguage(item))
+	require.Len(t, item.Authors, 1)
+	require.Equal(t, "Marie Curie", item.Authors[0].Name)
+	require.Equal(t, "http://example.org/episodes/1.png", item.Image.URL)
+	require.Len(t, item.Enclosures, 1)
+	require.Equal(t, &gofeed.Enclosure{URL: "http://example.org/episodes/1.mp3", Type: "audio/mpeg", Length: "89970236"}, item.Enclosures[0])
+
+	item = feed.Items[1]
+	require.Equal(t, "http://example.com/trailer", item.Link, "external urls should be used if the item has no url")
+	require.Equal(t, "http://example.org/banner.png", item.Image.URL)
+	require.Empty(t, fetch.ItemLanguage(item))
+	require.Len(t, item.Authors, 2, "items should be authored by the feed authors")
+}
+
+func TestJSONFeedError(t *testing.T) {
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		rw.Write([]byte(`{"version": "https://example.com/not-a-feed", "items": []}`))
+	})
+
+	_, err := fetch.NewFeedFetcher(url).Fetch(context.Background())
+	require.EqualError(t, err, "unsupported json feed version")
+}
+
 func TestSendETag(t *testing.T) {
 	// Make one reqeust that gets an etag response
 	// subsequent request should contain etag (and respond with 304)
@@ -112,3 +194,125 @@ func TestFeedError(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, herr.Code)
 	require.NotEmpty(t, herr.Status)
 }
+
+func TestRestoreFetcher(t *testing.T) {
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		if etag := req.Header.Get("If-None-Match"); etag == "ABCDEFG" {
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		FixtureHandler(t, "testdata/rss2.xml")(rw, req)
+	})
+
+	// A restored fetcher should send the conditional headers on its first request
+	fetcher := fetch.NewFeedFetcher(url)
+	fetcher.Restore("ABCDEFG", "")
+	require.Equal(t, "ABCDEFG", fetcher.ETag())
+
+	_, err := fetcher.Fetch(context.Background())
+	he, ok := err.(fetch.HTTPError)
+	require.True(t, ok, "did not return an HTTPError for restored etag")
+	require.True(t, he.NotModified())
+}
+
+func TestFeedRedirects(t *testing.T) {
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/moved":
+			http.Redirect(rw, req, "/permanent", http.StatusMovedPermanently)
+		case "/permanent":
+			http.Redirect(rw, req, "/feed.xml", http.StatusPermanentRedirect)
+		case "/found":
+			http.Redirect(rw, req, "/feed.xml", http.StatusFound)
+		case "/mixed":
+			http.Redirect(rw, req, "/found", http.StatusMovedPermanently)
+		case "/broken":
+			http.Redirect(rw, req, "/missing", http.StatusMovedPermanently)
+		case "/feed.xml":
+			FixtureHandler(t, "testdata/rss2.xml")(rw, req)
+		default:
+			http.NotFound(rw, req)
+		}
+	})
+
+	testCases := []struct {
+		path  string
+		moved string
+	}{
+		{"/feed.xml", ""},
+		{"/moved", "/feed.xml"},
+		{"/permanent", "/feed.xml"},
+		{"/found", ""},
+		{"/mixed", "/found"},
+	}
+
+	for _, tc := range testCases {
+		fetcher := fetch.NewFeedFetcher(url + tc.path)
+		feed, err := fetcher.Fetch(context.Background())
+		require.NoError(t, err, tc.path)
+		require.Equal(t, "Sample Feed", feed.Title, tc.path)
+
+		if tc.moved == "" {
+			require.Empty(t, fetcher.Moved(), tc.path)
+			require.Equal(t, url+tc.path, fetcher.URL(), "temporary redirects should not change the url")
+			continue
+		}
+
+		require.Equal(t, url+tc.moved, fetcher.Moved(), tc.path)
+		require.Equal(t, url+tc.moved, fetcher.URL(), tc.path)
+
+		// The next fetch should request the new url directly
+		_, err = fetcher.Fetch(context.Background())
+		require.NoError(t, err, tc.path)
+		require.Empty(t, fetcher.Moved(), tc.path)
+		require.Equal(t, url+tc.moved, fetcher.URL(), tc.path)
+	}
+
+	// A feed should not move to a url that does not respond successfully
+	fetcher := fetch.NewFeedFetcher(url + "/broken")
+	_, err := fetcher.Fetch(context.Background())
+	require.Error(t, err)
+	require.Empty(t, fetcher.Moved())
+	require.Equal(t, url+"/broken", fetcher.URL())
+}
+
+func TestFeedTTL(t *testing.T) {
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Cache-Control", "public, max-age=600")
+		FixtureHandler(t, "testdata/rss2.xml")(rw, req)
+	})
+
+	fetcher := fetch.NewFeedFetcher(url)
+	feed, err := fetcher.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, fetch.TTL(feed), "expected the rss ttl to be preserved")
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), fetcher.Expires(), 5*time.Second)
+
+	// The syndication module should be used if no ttl is specified
+	feed = &gofeed.Feed{
+		Extensions: ext.Extensions{
+			"sy": map[string][]ext.Extension{
+				"updatePeriod":    {{Name: "updatePeriod", Value: "daily"}},
+				"updateFrequency": {{Name: "updateFrequency", Value: "4"}},
+			},
+		},
+	}
+	require.Equal(t, 6*time.Hour, fetch.TTL(feed))
+
+	// No schedule hints should return zero
+	require.Zero(t, fetch.TTL(&gofeed.Feed{}))
+	require.Zero(t, fetch.TTL(nil))
+}
+
+func TestFeedExpires(t *testing.T) {
+	expires := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	url := NewServer(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Expires", expires.Format(http.TimeFormat))
+		FixtureHandler(t, "testdata/atom1.xml")(rw, req)
+	})
+
+	fetcher := fetch.NewFeedFetcher(url)
+	_, err := fetcher.Fetch(context.Background())
+	require.NoError(t, err)
+	require.True(t, expires.Equal(fetcher.Expires()), "expected expires header to be parsed")
+}
@@ -5,7 +5,11 @@ and preserve the state of the last request they made. They ensure that connectio
 closed and that resource use is minimized. For example, an RSS and Atom feed may need to
 be refreshed periodically, but to save bandwidth, we want to make sure we're respecting
 etag and modified headers as well as cache control. By creating a fetcher, we can
-repeatedly fetch the resource, minimizing bandwidth and being a good netizen.
+repeatedly fetch the resource, minimizing bandwidth and being a good netizen. Being a
+good netizen also means that the fetchers consult the robots.txt of each host (cached
+per host) before making a request, returning ErrDisallowed if Baleen is not allowed to
+fetch the url. Requests to each host are also rate limited by a token bucket that can be
+configured with SetRateLimit and that honors the Crawl-delay of the host's robots.txt.
 
 Right now the fecher can handle http and https requests, but future implementations may
 also include authenticated fetchers. There are currently two types of fetchers: the
@@ -64,7 +68,7 @@ func init() {
 const (
 	userAgent    = "Baleen/v1"
 	acceptHTML   = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
-	acceptRSS    = "application/atom+xml,application/rdf+xml,application/rss+xml,application/x-netcdf,application/xml;q=0.9,text/xml;q=0.2,*/*;q=0.1"
+	acceptRSS    = "application/atom+xml,application/rdf+xml,application/rss+xml,application/feed+json,application/x-netcdf,application/xml;q=0.9,application/json;q=0.8,text/xml;q=0.2,*/*;q=0.1"
 	acceptLang   = "*"
 	acceptEncode = "gzip;q=1.0, deflate,br;q=0.6, compress,identity;q=0.1"
 	referer      = ""
@@ -91,6 +95,9 @@ const (
 	HeaderLastModified    = "Last-Modified"
 	HeaderContentType     = "Content-Type"
 	HeaderContentEncoding = "Content-Encoding"
+	HeaderContentLanguage = "Content-Language"
+	HeaderExpires         = "Expires"
+	HeaderRetryAfter      = "Retry-After"
 )
 
 // SetClient allows you to specify an alternative http.Client to the default one
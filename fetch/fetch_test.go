@@ -33,6 +33,9 @@ func TestCanonicalHeaders(t *testing.T) {
 		fetch.HeaderLastModified,
 		fetch.HeaderContentType,
 		fetch.HeaderContentEncoding,
+		fetch.HeaderContentLanguage,
+		fetch.HeaderExpires,
+		fetch.HeaderRetryAfter,
 	}
 
 	for _, header := range headers {
@@ -40,20 +43,24 @@ func TestCanonicalHeaders(t *testing.T) {
 	}
 }
 
-// Helper function to create an http test server and set the fetch client.
+// Helper function to create an http test server and set the fetch client with no rate limit or retries.
 func NewServer(t *testing.T, handler http.HandlerFunc) string {
 	server := httptest.NewServer(handler)
 	t.Cleanup(server.Close)
 	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
 	t.Logf("test server open at %s", server.URL)
 	return server.URL
 }
 
-// Helper function to create an http TLS test server and set the fetch client.
+// Helper function to create an http TLS test server and set the fetch client with no rate limit or retries.
 func NewTLSServer(t *testing.T, handler http.HandlerFunc) string {
 	server := httptest.NewTLSServer(handler)
 	t.Cleanup(server.Close)
 	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
 	t.Logf("test server open at %s", server.URL)
 	return server.URL
 }
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/andybalholm/brotli"
@@ -26,8 +27,17 @@ type HTML struct {
 	content     *bytes.Buffer
 	ctype       string
 	encoding    string
+	clang       string
 	title       string
 	description string
+	lang        string
+	charset     string
+	decoded     []byte
+	text        string
+	article     *Article
+	meta        metadata
+	size        int64
+	parsed      bool
 }
 
 // NewHTMLFetcher creates a new HTML fetcher that can fetch the full HTML from the specified URL.
@@ -37,15 +47,40 @@ func NewHTMLFetcher(url string) *HTMLFetcher {
 	}
 }
 
+// NewHTML creates an HTML document from content that has already been retrieved, e.g.
+// the full content of a feed item, so that it can be parsed without being fetched.
+func NewHTML(content []byte, ctype string) *HTML {
+	return &HTML{
+		content: bytes.NewBuffer(content),
+		ctype:   ctype,
+		size:    int64(len(content)),
+	}
+}
+
 // The HTMLFetcher uses GET requests to retrieve the html containing the full text
 // of articles of feeds with a Baleen-specific http client.
+// Transient failures are retried according to the package retry policy.
 // TODO: return an HTML file instead of simply raw bytes (including document data).
 func (f *HTMLFetcher) Fetch(ctx context.Context) (html *HTML, err error) {
+	err = retryPolicy.do(ctx, func() error {
+		html, err = f.fetch(ctx)
+		return err
+	})
+	return html, err
+}
+
+func (f *HTMLFetcher) fetch(ctx context.Context) (html *HTML, err error) {
 	var req *http.Request
 	if req, err = f.newRequest(ctx); err != nil {
 		return nil, err
 	}
 
+	// Do not fetch the url if it is disallowed by the robots.txt of the host and wait
+	// until the request is allowed by the rate limit and Crawl-delay of the host.
+	if err = polite(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
 	var rep *http.Response
 	if rep, err = client.Do(req); err != nil {
 		return nil, err
@@ -60,10 +95,7 @@ func (f *HTMLFetcher) Fetch(ctx context.Context) (html *HTML, err error) {
 	// are still returning a 304 error to signal to the Subscription that nothing has
 	// changed and that the post is nil.
 	if rep.StatusCode < 200 || rep.StatusCode >= 300 {
-		return nil, HTTPError{
-			Status: rep.Status,
-			Code:   rep.StatusCode,
-		}
+		return nil, newHTTPError(rep)
 	}
 
 	// If ContentLength is -1 making this buffer will panic, so use a nil buffer to
@@ -79,9 +111,10 @@ func (f *HTMLFetcher) Fetch(ctx context.Context) (html *HTML, err error) {
 		content:  bytes.NewBuffer(buf),
 		ctype:    rep.Header.Get(HeaderContentType),
 		encoding: rep.Header.Get(HeaderContentEncoding),
+		clang:    rep.Header.Get(HeaderContentLanguage),
 	}
 
-	if _, err := io.Copy(html.content, rep.Body); err != nil {
+	if html.size, err = io.Copy(html.content, rep.Body); err != nil {
 		return nil, fmt.Errorf("could not read body retrieved from %s: %w", f.url, err)
 	}
 	return html, nil
@@ -102,14 +135,49 @@ func (f *HTMLFetcher) newRequest(ctx context.Context) (req *http.Request, err er
 	return req, nil
 }
 
-// Extract handles compression and content encoding from the response.
+// Size returns the number of bytes in the body of the response, before decompression.
+func (h *HTML) Size() int64 {
+	return h.size
+}
+
+// Extract handles compression and content encoding from the response and transcodes
+// the content to UTF-8 from the character set it was detected to be encoded with.
 func (h *HTML) Extract() (_ []byte, err error) {
+	if err = h.decode(); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), h.decoded...), nil
+}
+
+// Decompresses the content and transcodes it to UTF-8, caching the decoded content so
+// that it is only decoded once regardless of how many accessors are used.
+func (h *HTML) decode() (err error) {
+	if h.decoded != nil {
+		return nil
+	}
+
 	var reader io.ReadCloser
 	if reader, err = h.extract(); err != nil {
-		return nil, err
+		return err
 	}
 	defer reader.Close()
-	return io.ReadAll(reader)
+
+	var content []byte
+	if content, err = io.ReadAll(reader); err != nil {
+		return err
+	}
+
+	enc, name := detectCharset(content, h.ctype)
+	if name != "utf-8" {
+		if content, err = enc.NewDecoder().Bytes(content); err != nil {
+			return fmt.Errorf("could not transcode content from %s: %w", name, err)
+		}
+		content = declareUTF8(content)
+	}
+
+	h.charset = name
+	h.decoded = content
+	return nil
 }
 
 func (h *HTML) extract() (io.ReadCloser, error) {
@@ -135,32 +203,73 @@ func (h *HTML) extract() (io.ReadCloser, error) {
 }
 
 func (h *HTML) Title() string {
-	if h.title == "" {
+	if !h.parsed {
 		h.parse()
 	}
 	return h.title
 }
 
 func (h *HTML) Description() string {
-	if h.description == "" {
+	if !h.parsed {
 		h.parse()
 	}
 	return h.description
 }
 
+// Language returns the language declared by the lang attribute of the html element,
+// falling back to the first language in the Content-Language header of the response.
+func (h *HTML) Language() string {
+	if !h.parsed {
+		h.parse()
+	}
+
+	if h.lang != "" {
+		return h.lang
+	}
+
+	if lang, _, _ := strings.Cut(h.clang, ","); lang != "" {
+		return strings.TrimSpace(lang)
+	}
+	return ""
+}
+
+// Text returns the visible text of the body of the document with whitespace collapsed,
+// excluding scripts, styles, and other elements that are not rendered as text.
+func (h *HTML) Text() string {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.text
+}
+
+// Charset returns the character set that the content was encoded with, as declared by
+// the response or document or as detected from the content if it was not declared.
+// The content returned by Extract is always transcoded from this charset to UTF-8.
+func (h *HTML) Charset() string {
+	if h.decoded == nil {
+		h.decode()
+	}
+	return h.charset
+}
+
 func (h *HTML) parse() (err error) {
-	var reader io.ReadCloser
-	if reader, err = h.extract(); err != nil {
+	if err = h.decode(); err != nil {
 		return err
 	}
-	defer reader.Close()
 
 	var tree *goquery.Document
-	if tree, err = goquery.NewDocumentFromReader(reader); err != nil {
+	if tree, err = goquery.NewDocumentFromReader(bytes.NewReader(h.decoded)); err != nil {
 		return err
 	}
+	h.parsed = true
 
 	h.title = tree.Find("title").Contents().Text()
+	h.lang = strings.TrimSpace(tree.Find("html").AttrOr("lang", tree.Find("html").AttrOr("xml:lang", "")))
+
+	body := tree.Find("body").Clone()
+	body.Find("script, style, noscript, template, iframe, svg").Remove()
+	h.text = strings.Join(strings.Fields(body.Text()), " ")
+
 	tree.Find("meta").EachWithBreak(func(index int, item *goquery.Selection) bool {
 		if item.AttrOr("name", "") == "description" {
 			h.description = item.AttrOr("content", "")
@@ -169,5 +278,6 @@ func (h *HTML) parse() (err error) {
 		return true
 	})
 
+	h.meta = parseMetadata(tree)
 	return nil
 }
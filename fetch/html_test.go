@@ -3,10 +3,13 @@ package fetch_test
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rotationalio/baleen/fetch"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/html/charset"
 )
 
 func TestHTMLResponse(t *testing.T) {
@@ -21,6 +24,7 @@ func TestHTMLResponse(t *testing.T) {
 	data, err := html.Extract()
 	require.NoError(t, err)
 	require.Len(t, data, 1048)
+	require.Equal(t, int64(1048), html.Size())
 
 	require.Equal(t, "Hello World Post", html.Title())
 	require.Equal(t, "Just a quick test post", html.Description())
@@ -77,3 +81,192 @@ func TestEncodingError(t *testing.T) {
 	_, err = html.Extract()
 	require.EqualError(t, err, `unknown content encoding "frog"`)
 }
+
+func TestHTMLLanguageAndCharset(t *testing.T) {
+	// The fixture declares its language and charset in the document
+	url := NewServer(t, FixtureHandler(t, "testdata/post.html"))
+	html, err := fetch.NewHTMLFetcher(url).Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "en-US", html.Language())
+	require.Equal(t, "utf-8", html.Charset())
+
+	// Fall back to the response headers if the document declares nothing; the charset
+	// in the Content-Type header takes precedence over the document and is resolved to
+	// its canonical name (ISO-8859-1 is decoded as windows-1252 by browsers).
+	url = NewServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(fetch.HeaderContentType, "text/html; charset=ISO-8859-1")
+		w.Header().Set(fetch.HeaderContentLanguage, "de-DE, en-CA")
+		w.Write([]byte(`<html><head><meta charset="utf-8"><title>Hallo Welt</title></head><body></body></html>`))
+	})
+
+	html, err = fetch.NewHTMLFetcher(url).Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "de-DE", html.Language())
+	require.Equal(t, "windows-1252", html.Charset())
+	require.Equal(t, "Hallo Welt", html.Title())
+}
+
+func TestHTMLText(t *testing.T) {
+	url := NewServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(fetch.HeaderContentType, "text/html; charset=utf-8")
+		w.Write([]byte(`<html><head><title>Title</title><style>p { color: red; }</style></head>
+<body><h1>Hello   World</h1>
+<script>var x = 1;</script>
+<p>This is the
+  first paragraph.</p><noscript>Enable JavaScript</noscript></body></html>`))
+	})
+
+	html, err := fetch.NewHTMLFetcher(url).Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "Hello World This is the first paragraph.", html.Text())
+}
+
+func TestHTMLTranscoding(t *testing.T) {
+	testCases := []struct {
+		name        string
+		charset     string
+		contentType string
+		meta        string
+		utf8Meta    string
+		text        string
+	}{
+		{"declared header", "shift_jis", "text/html; charset=Shift_JIS", "", "", "天気予報によると、明日の午後は雨が降るそうです。"},
+		{"declared meta", "windows-1251", "text/html", `<meta charset="windows-1251">`, `<meta charset="utf-8">`, "Прогноз погоды обещает дождь завтра после обеда."},
+		{"declared http-equiv", "windows-1252", "text/html", `<meta http-equiv="Content-Type" content="text/html; charset=ISO-8859-1">`, `<meta http-equiv="Content-Type" content="text/html; charset=utf-8">`, "La météo annonce de la pluie pour demain après-midi."},
+		{"declared header and meta", "shift_jis", "text/html; charset=Shift_JIS", `<meta http-equiv="Content-Type" content="text/html; charset=Shift_JIS">`, `<meta http-equiv="Content-Type" content="text/html; charset=utf-8">`, "天気予報によると、明日の午後は雨が降るそうです。"},
+		{"sniffed shift_jis", "shift_jis", "text/html", "", "", "天気予報によると、明日の午後は雨が降るそうなので、試合には傘を持っていきましょう。"},
+		{"sniffed gb18030", "gb18030", "text/html", "", "", "天气预报说明天下午会下雨，所以我们应该带一把伞去看比赛。"},
+		{"sniffed euc-kr", "euc-kr", "text/html", "", "", "일기 예보에 따르면 내일 오후에 비가 온다고 하니 경기에 우산을 가져가야 합니다."},
+		{"sniffed windows-1251", "windows-1251", "text/html", "", "", "Прогноз погоды обещает дождь завтра после обеда, поэтому нам стоит взять с собой зонтик на матч."},
+		{"sniffed windows-1252", "windows-1252", "text/html", "", "", "La météo annonce de la pluie pour demain après-midi, alors nous devrions emporter un parapluie."},
+		{"utf-8", "utf-8", "text/html", `<meta charset="utf-8">`, `<meta charset="utf-8">`, "La météo annonce de la pluie pour demain après-midi."},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page := "<html><head>" + tc.meta + "<title>Test</title></head><body><p>" + tc.text + "</p></body></html>"
+
+			body := []byte(page)
+			if tc.charset != "utf-8" {
+				enc, _ := charset.Lookup(tc.charset)
+				require.NotNil(t, enc, "unknown charset %s", tc.charset)
+
+				var err error
+				body, err = enc.NewEncoder().Bytes(body)
+				require.NoError(t, err, "could not encode test page")
+			}
+
+			url := NewServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(fetch.HeaderContentType, tc.contentType)
+				w.Write(body)
+			})
+
+			html, err := fetch.NewHTMLFetcher(url).Fetch(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tc.charset, html.Charset())
+			require.Equal(t, tc.text, html.Text())
+
+			// The declared charset should be rewritten since the content is now utf-8
+			content, err := html.Extract()
+			require.NoError(t, err)
+			expected := "<html><head>" + tc.utf8Meta + "<title>Test</title></head><body><p>" + tc.text + "</p></body></html>"
+			require.Equal(t, expected, string(content), "content was not transcoded to utf-8")
+			require.Equal(t, "utf-8", fetch.NewHTML(content, "text/html").Charset(), "transcoded content should declare utf-8")
+		})
+	}
+
+	// The encoding of the XML declaration of XHTML documents is also rewritten
+	url := NewServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(fetch.HeaderContentType, "application/xhtml+xml; charset=ISO-8859-1")
+		w.Write([]byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><html><head><title>M\xe9t\xe9o</title></head></html>"))
+	})
+
+	html, err := fetch.NewHTMLFetcher(url).Fetch(context.Background())
+	require.NoError(t, err)
+
+	content, err := html.Extract()
+	require.NoError(t, err)
+	require.Equal(t, `<?xml version="1.0" encoding="utf-8"?><html><head><title>Météo</title></head></html>`, string(content))
+}
+
+func TestHTMLArticle(t *testing.T) {
+	url := NewServer(t, FixtureHandler(t, "testdata/article.html"))
+	html, err := fetch.NewHTMLFetcher(url).Fetch(context.Background())
+	require.NoError(t, err)
+
+	article := html.Article()
+	require.NotEmpty(t, article.Text)
+	require.NotEmpty(t, article.HTML)
+
+	// The main content should be in the text, separated into paragraphs
+	paragraphs := strings.Split(article.Text, "\n\n")
+	require.Contains(t, paragraphs, "The Secret Lives of Baleen Whales")
+	require.Contains(t, article.Text, "Baleen whales are the largest animals that have ever lived")
+	require.Contains(t, article.Text, "A single gulp can contain more water than the whale's own body.")
+	require.Contains(t, paragraphs, "A humpback whale breaching off the coast of Alaska.")
+
+	// The boilerplate should not be in the text or the fragment
+	for _, boilerplate := range []string{"Environment", "Subscribe today", "Tweet", "Most Popular", "great article", "Copyright", "dataLayer", "font-family"} {
+		require.NotContains(t, article.Text, boilerplate)
+		require.NotContains(t, article.HTML, boilerplate)
+	}
+
+	// The fragment should only keep the attributes needed for links and images
+	require.True(t, strings.HasPrefix(article.HTML, "<div>"))
+	require.Contains(t, article.HTML, `<img src="/images/humpback.jpg" alt="A humpback whale breaching"/>`)
+	require.Contains(t, article.HTML, `<a href="/authors/jane">Jane Doe</a>`)
+	require.NotContains(t, article.HTML, "class=")
+	require.NotContains(t, article.HTML, "data-lazy")
+
+	// The raw content should not be modified by the extraction
+	content, err := html.Extract()
+	require.NoError(t, err)
+	require.Contains(t, string(content), "Most Popular")
+}
+
+func TestHTMLMetadata(t *testing.T) {
+	url := NewServer(t, FixtureHandler(t, "testdata/metadata.html"))
+	html, err := fetch.NewHTMLFetcher(url).Fetch(context.Background())
+	require.NoError(t, err)
+
+	require.Equal(t, "https://oceannews.example.com/2023/06/humpbacks-return", html.Canonical())
+	require.Equal(t, "Ocean News", html.SiteName())
+	require.Equal(t, "Jane Doe, John Roe", html.Author())
+	require.Equal(t, []string{"whales", "humpback", "Gulf of Maine", "conservation"}, html.Keywords())
+	require.True(t, html.Published().Equal(time.Date(2023, time.June, 5, 12, 30, 0, 0, time.UTC)))
+	require.True(t, html.Modified().Equal(time.Date(2023, time.June, 6, 14, 15, 0, 0, time.UTC)))
+
+	og := html.OpenGraph()
+	require.Len(t, og, 5)
+	require.Equal(t, "article", og["og:type"])
+	require.Equal(t, "https://oceannews.example.com/images/humpback.jpg", og["og:image"])
+
+	require.Equal(t, map[string]string{
+		"twitter:card":    "summary_large_image",
+		"twitter:site":    "@oceannews",
+		"twitter:creator": "@janedoe",
+	}, html.TwitterCard())
+
+	ld := html.LinkedData()
+	require.NotNil(t, ld)
+	require.Equal(t, "NewsArticle", ld.Type)
+	require.Equal(t, "Humpbacks Return to the Gulf of Maine", ld.Headline)
+	require.Equal(t, []string{"Jane Doe", "John Roe"}, ld.Authors)
+	require.Equal(t, "Ocean News Media", ld.Publisher)
+	require.Equal(t, "https://oceannews.example.com/images/humpback.jpg", ld.Image)
+	require.Equal(t, []string{"whales", "conservation"}, ld.Keywords)
+	require.Equal(t, "Science", ld.Section)
+	require.True(t, ld.Published.Equal(time.Date(2023, time.June, 5, 12, 30, 0, 0, time.UTC)))
+	require.Contains(t, string(ld.Raw), `"headline":"Humpbacks Return to the Gulf of Maine"`)
+
+	// The canonical url falls back to the OpenGraph url and missing metadata is empty
+	url = NewServer(t, FixtureHandler(t, "testdata/post.html"))
+	html, err = fetch.NewHTMLFetcher(url).Fetch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "http://example.com/post", html.Canonical())
+	require.Equal(t, "Hello World Post", html.OpenGraph()["og:title"])
+	require.Empty(t, html.Author())
+	require.Empty(t, html.Keywords())
+	require.Zero(t, html.Published())
+	require.Nil(t, html.LinkedData())
+}
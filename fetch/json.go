@@ -0,0 +1,269 @@
+package fetch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// The prefix of the version url of JSON Feed 1.0 and 1.1 documents.
+const jsonFeedVersion = "https://jsonfeed.org/version/1"
+
+// The keys in the gofeed.Item Custom map used to preserve JSON Feed item fields that are
+// otherwise not represented by the universal feed item.
+const (
+	customContentText = "content_text"
+	customLanguage    = "language"
+)
+
+// ContentText returns the plain text content of a JSON Feed item, which is preserved
+// separately from the HTML content of the item. An empty string is returned for items
+// from other types of feeds or if the item does not have plain text content.
+func ContentText(item *gofeed.Item) string {
+	if item == nil {
+		return ""
+	}
+	return item.Custom[customContentText]
+}
+
+// ItemLanguage returns the language of a JSON Feed 1.1 item, which may differ from the
+// language of the feed. An empty string is returned if the item does not specify one.
+func ItemLanguage(item *gofeed.Item) string {
+	if item == nil {
+		return ""
+	}
+	return item.Custom[customLanguage]
+}
+
+// jsonFeed is a JSON Feed version 1.0 or 1.1 document.
+// SEE: https://www.jsonfeed.org/version/1.1/
+type jsonFeed struct {
+	Version     string        `json:"version"`
+	Title       string        `json:"title"`
+	HomePageURL string        `json:"home_page_url"`
+	FeedURL     string        `json:"feed_url"`
+	Description string        `json:"description"`
+	Icon        string        `json:"icon"`
+	Favicon     string        `json:"favicon"`
+	Language    string        `json:"language"`
+	Author      *jsonAuthor   `json:"author"`  // deprecated in 1.1 in favor of authors
+	Authors     []*jsonAuthor `json:"authors"` // new in 1.1
+	Items       []*jsonItem   `json:"items"`
+}
+
+type jsonItem struct {
+	ID            jsonID           `json:"id"`
+	URL           string           `json:"url"`
+	ExternalURL   string           `json:"external_url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	ContentText   string           `json:"content_text"`
+	Summary       string           `json:"summary"`
+	Image         string           `json:"image"`
+	BannerImage   string           `json:"banner_image"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Author        *jsonAuthor      `json:"author"`
+	Authors       []*jsonAuthor    `json:"authors"`
+	Tags          []string         `json:"tags"`
+	Language      string           `json:"language"`
+	Attachments   []jsonAttachment `json:"attachments"`
+}
+
+type jsonAuthor struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Avatar string `json:"avatar"`
+}
+
+type jsonAttachment struct {
+	URL               string  `json:"url"`
+	MimeType          string  `json:"mime_type"`
+	Title             string  `json:"title"`
+	SizeInBytes       int64   `json:"size_in_bytes"`
+	DurationInSeconds float64 `json:"duration_in_seconds"`
+}
+
+// The id of a JSON Feed item should be a string but readers must coerce other types,
+// e.g. numbers, to a string.
+type jsonID string
+
+func (id *jsonID) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*id = jsonID(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("invalid json feed item id %s", data)
+	}
+	*id = jsonID(n.String())
+	return nil
+}
+
+// Returns true if the response is a JSON Feed, either because of its content type or
+// because its body is a JSON object (since many servers send JSON Feeds as text/plain).
+func isJSONFeed(ctype string, body []byte) bool {
+	if mediatype, _, err := mime.ParseMediaType(ctype); err == nil {
+		switch mediatype {
+		case "application/feed+json", "application/json":
+			return true
+		}
+	}
+
+	body = bytes.TrimLeft(bytes.TrimPrefix(body, []byte("\xef\xbb\xbf")), " \t\r\n")
+	return len(body) > 0 && body[0] == '{'
+}
+
+// Parses a JSON Feed into the universal feed so that JSON Feeds are handled in the same
+// manner as RSS and Atom feeds. Fields that the universal feed does not represent are
+// preserved in the Custom map of the feed items (see ContentText and ItemLanguage).
+func parseJSONFeed(data []byte) (_ *gofeed.Feed, err error) {
+	doc := &jsonFeed{}
+	if err = json.Unmarshal(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")), doc); err != nil {
+		return nil, fmt.Errorf("could not parse json feed: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.Version, jsonFeedVersion) {
+		return nil, errors.New("unsupported json feed version")
+	}
+
+	feed := &gofeed.Feed{
+		Title:       doc.Title,
+		Description: doc.Description,
+		Link:        doc.HomePageURL,
+		FeedLink:    doc.FeedURL,
+		Language:    doc.Language,
+		Authors:     jsonAuthors(doc.Authors, doc.Author, nil),
+		FeedType:    "json",
+		FeedVersion: "1" + strings.TrimPrefix(doc.Version, jsonFeedVersion),
+		Items:       make([]*gofeed.Item, 0, len(doc.Items)),
+	}
+
+	// Version urls are https://jsonfeed.org/version/1 and https://jsonfeed.org/version/1.1
+	if feed.FeedVersion == "1" {
+		feed.FeedVersion = "1.0"
+	}
+
+	for _, link := range []string{doc.HomePageURL, doc.FeedURL} {
+		if link != "" {
+			feed.Links = append(feed.Links, link)
+		}
+	}
+
+	if len(feed.Authors) > 0 {
+		feed.Author = feed.Authors[0]
+	}
+
+	if icon := first(doc.Icon, doc.Favicon); icon != "" {
+		feed.Image = &gofeed.Image{URL: icon}
+	}
+
+	for _, src := range doc.Items {
+		if src == nil {
+			continue
+		}
+
+		item := &gofeed.Item{
+			GUID:        string(src.ID),
+			Title:       src.Title,
+			Description: src.Summary,
+			Content:     first(src.ContentHTML, src.ContentText),
+			Link:        first(src.URL, src.ExternalURL),
+			Published:   src.DatePublished,
+			Updated:     src.DateModified,
+			Categories:  src.Tags,
+			Custom:      make(map[string]string),
+		}
+
+		for _, link := range []string{src.URL, src.ExternalURL} {
+			if link != "" {
+				item.Links = append(item.Links, link)
+			}
+		}
+
+		// Items without authors are authored by the authors of the feed
+		item.Authors = jsonAuthors(src.Authors, src.Author, feed.Authors)
+		if len(item.Authors) > 0 {
+			item.Author = item.Authors[0]
+		}
+
+		if image := first(src.Image, src.BannerImage); image != "" {
+			item.Image = &gofeed.Image{URL: image}
+		}
+
+		if ts := parseTime(src.DatePublished); !ts.IsZero() {
+			item.PublishedParsed = &ts
+		}
+
+		if ts := parseTime(src.DateModified); !ts.IsZero() {
+			item.UpdatedParsed = &ts
+		}
+
+		for _, attachment := range src.Attachments {
+			if attachment.URL == "" {
+				continue
+			}
+
+			enclosure := &gofeed.Enclosure{URL: attachment.URL, Type: attachment.MimeType}
+			if attachment.SizeInBytes > 0 {
+				enclosure.Length = strconv.FormatInt(attachment.SizeInBytes, 10)
+			}
+			item.Enclosures = append(item.Enclosures, enclosure)
+		}
+
+		if src.ContentText != "" {
+			item.Custom[customContentText] = src.ContentText
+		}
+
+		if src.Language != "" {
+			item.Custom[customLanguage] = src.Language
+		}
+
+		feed.Items = append(feed.Items, item)
+	}
+
+	// JSON Feeds do not have dates of their own so the feed is dated by its latest items
+	for _, item := range feed.Items {
+		if item.PublishedParsed != nil && (feed.PublishedParsed == nil || item.PublishedParsed.After(*feed.PublishedParsed)) {
+			feed.Published, feed.PublishedParsed = item.Published, item.PublishedParsed
+		}
+
+		if item.UpdatedParsed != nil && (feed.UpdatedParsed == nil || item.UpdatedParsed.After(*feed.UpdatedParsed)) {
+			feed.Updated, feed.UpdatedParsed = item.Updated, item.UpdatedParsed
+		}
+	}
+	return feed, nil
+}
+
+// Returns the 1.1 authors if specified, falling back to the deprecated 1.0 author and
+// then to the specified fallback authors. Authors that only have a url are named by it.
+func jsonAuthors(authors []*jsonAuthor, author *jsonAuthor, fallback []*gofeed.Person) []*gofeed.Person {
+	if len(authors) == 0 && author != nil {
+		authors = []*jsonAuthor{author}
+	}
+
+	people := make([]*gofeed.Person, 0, len(authors))
+	for _, author := range authors {
+		if author == nil {
+			continue
+		}
+
+		if name := first(strings.TrimSpace(author.Name), author.URL); name != "" {
+			people = append(people, &gofeed.Person{Name: name})
+		}
+	}
+
+	if len(people) == 0 {
+		return fallback
+	}
+	return people
+}
@@ -0,0 +1,164 @@
+package fetch
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit configures the per-host politeness of the fetchers. Requests to each host
+// are limited by a token bucket that refills at Rate tokens per second up to Burst
+// tokens. Domains overrides the rate for hosts in specific domains (e.g. example.com
+// also applies to www.example.com); the most specific domain is used. If the rate is
+// zero then requests to the host are not limited except by the Crawl-delay of its
+// robots.txt, which always limits the rate to at most one request per delay.
+type RateLimit struct {
+	Rate    float64
+	Burst   int
+	Domains map[string]float64
+}
+
+// DefaultRateLimit allows one request per second to each host with bursts of two.
+var DefaultRateLimit = RateLimit{Rate: 1, Burst: 2}
+
+// A package level rate limiter that is used by all http based fetchers in this package
+// before making a request. It can be modified using the SetRateLimit function.
+var limiter = newRateLimiter(DefaultRateLimit)
+
+// SetRateLimit replaces the rate limiter used by all http based fetchers in this package.
+// Use this function to configure the politeness of the fetchers or to disable the rate
+// limit for testing.
+func SetRateLimit(limit RateLimit) {
+	limiter = newRateLimiter(limit)
+}
+
+// Maintains a token bucket for each host, keyed by the host and port of the url.
+type rateLimiter struct {
+	sync.Mutex
+	limit   RateLimit
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	sync.Mutex
+	rate   float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+
+	domains := make(map[string]float64, len(limit.Domains))
+	for domain, rate := range limit.Domains {
+		domains[strings.ToLower(strings.Trim(domain, "."))] = rate
+	}
+	limit.Domains = domains
+
+	return &rateLimiter{
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Checks the robots.txt of the host and waits until a request to the url is allowed
+// by both the rate limit and the Crawl-delay of the host. Every request made by the
+// fetchers in this package should be preceded by a call to polite.
+func polite(ctx context.Context, u *url.URL) (err error) {
+	var delay time.Duration
+	if delay, err = robots.check(ctx, u); err != nil {
+		return err
+	}
+	return limiter.wait(ctx, u, delay)
+}
+
+// Blocks until a token is available for the host of the url or the context is done. If
+// the host specifies a Crawl-delay that is slower than its rate, the delay is used.
+func (l *rateLimiter) wait(ctx context.Context, u *url.URL, delay time.Duration) error {
+	b := l.bucket(u)
+	rate, burst := l.rate(u.Hostname()), float64(l.limit.Burst)
+	if delay > 0 {
+		if crawl := 1 / delay.Seconds(); rate <= 0 || crawl < rate {
+			rate, burst = crawl, 1
+		}
+	}
+
+	if rate <= 0 {
+		return nil
+	}
+	return b.wait(ctx, rate, burst)
+}
+
+func (l *rateLimiter) bucket(u *url.URL) *bucket {
+	l.Lock()
+	defer l.Unlock()
+
+	host := strings.ToLower(u.Host)
+	b, ok := l.buckets[host]
+	if !ok {
+		b = &bucket{}
+		l.buckets[host] = b
+	}
+	return b
+}
+
+// Returns the rate of the most specific domain of the host that has an override, or
+// the default rate if none of the domains of the host have an override.
+func (l *rateLimiter) rate(host string) float64 {
+	domain := strings.ToLower(host)
+	for {
+		if rate, ok := l.limit.Domains[domain]; ok {
+			return rate
+		}
+
+		var found bool
+		if _, domain, found = strings.Cut(domain, "."); !found {
+			return l.limit.Rate
+		}
+	}
+}
+
+// Reserves a token from the bucket, refilling it at the rate since the last request,
+// and waits until the token is available. If the context is done before the token is
+// available then the reservation is cancelled.
+func (b *bucket) wait(ctx context.Context, rate, burst float64) error {
+	b.Lock()
+	now := time.Now()
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+	}
+
+	// The rate may change if the Crawl-delay of the host is discovered or changes
+	b.rate = rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+
+	b.last = now
+	b.tokens--
+	wait := time.Duration(-b.tokens / rate * float64(time.Second))
+	b.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.Lock()
+		b.tokens++
+		b.Unlock()
+		return ctx.Err()
+	}
+}
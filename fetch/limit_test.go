@@ -0,0 +1,55 @@
+package fetch_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rotationalio/baleen/fetch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	url := NewServer(t, robotsHandler(http.StatusNotFound, ""))
+	fetch.SetRateLimit(fetch.RateLimit{Rate: 10, Burst: 2})
+	t.Cleanup(func() { fetch.SetRateLimit(fetch.RateLimit{}) })
+
+	// The burst is allowed immediately, then one request every 100ms
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := fetch.NewHTMLFetcher(url + "/").Fetch(context.Background())
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond, "expected the rate limit to be enforced")
+
+	// The wait for the rate limit should be cut short if the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := fetch.NewHTMLFetcher(url + "/").Fetch(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimitDomains(t *testing.T) {
+	url := NewServer(t, robotsHandler(http.StatusNotFound, ""))
+
+	// The test server is not limited by its domain override despite the slow default
+	fetch.SetRateLimit(fetch.RateLimit{Rate: 0.1, Burst: 1, Domains: map[string]float64{"127.0.0.1": 0}})
+	t.Cleanup(func() { fetch.SetRateLimit(fetch.RateLimit{}) })
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := fetch.NewHTMLFetcher(url + "/").Fetch(context.Background())
+		require.NoError(t, err)
+	}
+	require.Less(t, time.Since(start), time.Second, "expected the domain override to be used")
+
+	// The Crawl-delay of the host limits the rate even if the host is not limited
+	url = NewServer(t, robotsHandler(http.StatusOK, "User-agent: *\nCrawl-delay: 0.1\n"))
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := fetch.NewHTMLFetcher(url + "/").Fetch(context.Background())
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond, "expected the crawl delay to be honored")
+}
@@ -0,0 +1,378 @@
+package fetch
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// LinkedData is the schema.org Article described by the JSON-LD of a document. The Raw
+// field contains the JSON of the article object exactly as it was found in the document.
+type LinkedData struct {
+	Type        string
+	Headline    string
+	Description string
+	Authors     []string
+	Publisher   string
+	Published   time.Time
+	Modified    time.Time
+	Image       string
+	Keywords    []string
+	Section     string
+	URL         string
+	Raw         json.RawMessage
+}
+
+// The schema.org types that describe an article.
+var articleTypes = map[string]struct{}{
+	"Article":                  {},
+	"NewsArticle":              {},
+	"BlogPosting":              {},
+	"Report":                   {},
+	"ScholarlyArticle":         {},
+	"TechArticle":              {},
+	"AnalysisNewsArticle":      {},
+	"OpinionNewsArticle":       {},
+	"ReportageNews":            {},
+	"ReviewNewsArticle":        {},
+	"BackgroundNewsArticle":    {},
+	"LiveBlogPosting":          {},
+	"SocialMediaPosting":       {},
+	"DiscussionForumPosting":   {},
+	"AdvertiserContentArticle": {},
+	"SatiricalArticle":         {},
+}
+
+// Formats used by documents to specify published and modified times.
+var timeFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// Metadata extracted from the head of the document when it is parsed.
+type metadata struct {
+	canonical   string
+	author      string
+	siteName    string
+	keywords    []string
+	published   time.Time
+	modified    time.Time
+	openGraph   map[string]string
+	twitterCard map[string]string
+	linkedData  *LinkedData
+}
+
+// Canonical returns the canonical url of the document, falling back to the OpenGraph url.
+func (h *HTML) Canonical() string {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.canonical
+}
+
+// Author returns the author of the document from its meta tags or JSON-LD.
+func (h *HTML) Author() string {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.author
+}
+
+// SiteName returns the name of the site the document was published on.
+func (h *HTML) SiteName() string {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.siteName
+}
+
+// Keywords returns the keywords and tags of the document.
+func (h *HTML) Keywords() []string {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.keywords
+}
+
+// Published returns when the document was published or the zero time if unknown.
+func (h *HTML) Published() time.Time {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.published
+}
+
+// Modified returns when the document was last modified or the zero time if unknown.
+func (h *HTML) Modified() time.Time {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.modified
+}
+
+// OpenGraph returns the OpenGraph properties of the document keyed by property name,
+// e.g. og:title. Only the first value of properties that are specified more than once
+// is returned.
+func (h *HTML) OpenGraph() map[string]string {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.openGraph
+}
+
+// TwitterCard returns the Twitter card properties of the document keyed by property
+// name, e.g. twitter:card.
+func (h *HTML) TwitterCard() map[string]string {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.twitterCard
+}
+
+// LinkedData returns the schema.org Article described by the JSON-LD of the document,
+// or nil if the document does not describe an article with JSON-LD.
+func (h *HTML) LinkedData() *LinkedData {
+	if !h.parsed {
+		h.parse()
+	}
+	return h.meta.linkedData
+}
+
+func parseMetadata(tree *goquery.Document) (meta metadata) {
+	meta.openGraph = make(map[string]string)
+	meta.twitterCard = make(map[string]string)
+	named := make(map[string]string)
+
+	tree.Find("meta").Each(func(_ int, item *goquery.Selection) {
+		content := strings.TrimSpace(item.AttrOr("content", ""))
+		if content == "" {
+			return
+		}
+
+		// OpenGraph uses the property attribute though many sites use the name attribute
+		for _, attr := range []string{"property", "name", "itemprop"} {
+			key := strings.ToLower(strings.TrimSpace(item.AttrOr(attr, "")))
+			if key == "" {
+				continue
+			}
+
+			var props map[string]string
+			switch {
+			case strings.HasPrefix(key, "og:"):
+				props = meta.openGraph
+			case strings.HasPrefix(key, "twitter:"):
+				props = meta.twitterCard
+			default:
+				props = named
+			}
+
+			if _, ok := props[key]; !ok {
+				props[key] = content
+			}
+		}
+	})
+
+	tree.Find(`script[type="application/ld+json"]`).EachWithBreak(func(_ int, script *goquery.Selection) bool {
+		meta.linkedData = parseLinkedData([]byte(script.Text()))
+		return meta.linkedData == nil
+	})
+
+	ld := meta.linkedData
+	if ld == nil {
+		ld = &LinkedData{}
+	}
+
+	meta.canonical = first(tree.Find(`link[rel="canonical"]`).AttrOr("href", ""), meta.openGraph["og:url"], ld.URL)
+	meta.siteName = first(meta.openGraph["og:site_name"], named["application-name"], ld.Publisher)
+	meta.author = first(named["author"], named["article:author"], strings.Join(ld.Authors, ", "), meta.twitterCard["twitter:creator"])
+	meta.published = firstTime(parseTime(named["article:published_time"]), parseTime(named["datepublished"]), ld.Published)
+	meta.modified = firstTime(parseTime(named["article:modified_time"]), parseTime(named["datemodified"]), parseTime(meta.openGraph["og:updated_time"]), ld.Modified)
+
+	if keywords := named["keywords"]; keywords != "" {
+		meta.keywords = splitKeywords(keywords)
+	} else if len(ld.Keywords) > 0 {
+		meta.keywords = ld.Keywords
+	}
+
+	tree.Find(`meta[property="article:tag"]`).Each(func(_ int, item *goquery.Selection) {
+		if tag := strings.TrimSpace(item.AttrOr("content", "")); tag != "" {
+			meta.keywords = appendUnique(meta.keywords, tag)
+		}
+	})
+
+	return meta
+}
+
+// Parses the JSON-LD and returns the first schema.org Article that it describes,
+// searching through arrays and @graph containers.
+func parseLinkedData(data []byte) *LinkedData {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+
+	obj := findArticle(doc)
+	if obj == nil {
+		return nil
+	}
+
+	ld := &LinkedData{
+		Headline:    jsonString(obj["headline"]),
+		Description: jsonString(obj["description"]),
+		Authors:     jsonNames(obj["author"]),
+		Publisher:   first(jsonNames(obj["publisher"])...),
+		Published:   parseTime(jsonString(obj["datePublished"])),
+		Modified:    parseTime(jsonString(obj["dateModified"])),
+		Image:       first(jsonURLs(obj["image"])...),
+		Section:     first(jsonStrings(obj["articleSection"])...),
+		URL:         first(jsonString(obj["url"]), jsonString(obj["@id"])),
+	}
+
+	if types := jsonStrings(obj["@type"]); len(types) > 0 {
+		ld.Type = types[0]
+	}
+
+	for _, keywords := range jsonStrings(obj["keywords"]) {
+		for _, keyword := range splitKeywords(keywords) {
+			ld.Keywords = appendUnique(ld.Keywords, keyword)
+		}
+	}
+
+	ld.Raw, _ = json.Marshal(obj)
+	return ld
+}
+
+func findArticle(doc interface{}) map[string]interface{} {
+	switch v := doc.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if obj := findArticle(item); obj != nil {
+				return obj
+			}
+		}
+	case map[string]interface{}:
+		for _, t := range jsonStrings(v["@type"]) {
+			if _, ok := articleTypes[t]; ok {
+				return v
+			}
+		}
+
+		if graph, ok := v["@graph"]; ok {
+			return findArticle(graph)
+		}
+	}
+	return nil
+}
+
+func jsonString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return ""
+}
+
+// Returns the strings in a JSON-LD value that may either be a string or an array.
+func jsonStrings(v interface{}) (values []string) {
+	switch v := v.(type) {
+	case string:
+		if s := strings.TrimSpace(v); s != "" {
+			values = append(values, s)
+		}
+	case []interface{}:
+		for _, item := range v {
+			values = append(values, jsonStrings(item)...)
+		}
+	}
+	return values
+}
+
+// Returns the names of JSON-LD values that may be strings, objects with a name, or
+// arrays of either, e.g. the author or publisher of an article.
+func jsonNames(v interface{}) (names []string) {
+	switch v := v.(type) {
+	case string:
+		names = jsonStrings(v)
+	case map[string]interface{}:
+		names = jsonStrings(v["name"])
+	case []interface{}:
+		for _, item := range v {
+			names = append(names, jsonNames(item)...)
+		}
+	}
+	return names
+}
+
+// Returns the urls of JSON-LD values that may be strings, objects with a url, or
+// arrays of either, e.g. the images of an article.
+func jsonURLs(v interface{}) (urls []string) {
+	switch v := v.(type) {
+	case string:
+		urls = jsonStrings(v)
+	case map[string]interface{}:
+		urls = jsonStrings(v["url"])
+	case []interface{}:
+		for _, item := range v {
+			urls = append(urls, jsonURLs(item)...)
+		}
+	}
+	return urls
+}
+
+func parseTime(value string) time.Time {
+	if value = strings.TrimSpace(value); value == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range timeFormats {
+		if ts, err := time.Parse(layout, value); err == nil {
+			return ts
+		}
+	}
+	return time.Time{}
+}
+
+func splitKeywords(keywords string) (values []string) {
+	for _, keyword := range strings.Split(keywords, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			values = appendUnique(values, keyword)
+		}
+	}
+	return values
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// Returns the first non-empty value.
+func first(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Returns the first non-zero time.
+func firstTime(values ...time.Time) time.Time {
+	for _, value := range values {
+		if !value.IsZero() {
+			return value
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,152 @@
+package fetch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures how the fetchers retry requests that fail with a transient
+// error, e.g. a network error, a timeout, or a 429 or 5xx response. Requests are
+// retried up to MaxRetries times, waiting for an exponentially increasing interval
+// that starts at InitialInterval, is multiplied by Multiplier after every retry, and is
+// capped at MaxInterval. Each interval is randomized by plus or minus Jitter (a
+// fraction of the interval) so that failed requests to a host are not retried in
+// lockstep. If the response specifies a Retry-After that is longer than the interval
+// then the request is retried after that delay instead, unless it is longer than the
+// MaxInterval, in which case the request is not retried.
+type RetryPolicy struct {
+	MaxRetries      int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+	Jitter          float64
+}
+
+// DefaultRetryPolicy retries transient failures three times, after about 1, 2 and 4s.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:      3,
+	InitialInterval: 1 * time.Second,
+	MaxInterval:     30 * time.Second,
+	Multiplier:      2,
+	Jitter:          0.2,
+}
+
+// A package level retry policy that is used by all http based fetchers in this package.
+// It can be modified using the SetRetryPolicy function.
+var retryPolicy = DefaultRetryPolicy
+
+// SetRetryPolicy replaces the retry policy used by all http based fetchers in this
+// package. Use this function to configure retries or to disable them for testing.
+func SetRetryPolicy(policy RetryPolicy) {
+	retryPolicy = policy
+}
+
+// Retryable returns true if the error is a transient failure that may succeed if the
+// request is retried: a network error or timeout, or an HTTP error with a status code
+// that indicates the server is temporarily unable to handle the request. Errors caused
+// by the context being cancelled and urls disallowed by robots.txt are not retryable.
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, ErrDisallowed) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var httperr HTTPError
+	if errors.As(err, &httperr) {
+		return httperr.Temporary()
+	}
+
+	// DNS errors are only transient if the lookup timed out or failed temporarily
+	var dnserr *net.DNSError
+	if errors.As(err, &dnserr) {
+		return dnserr.IsTimeout || dnserr.IsTemporary
+	}
+
+	// Timeouts and failures to dial or read from the connection are transient
+	var neterr net.Error
+	if errors.As(err, &neterr) && neterr.Timeout() {
+		return true
+	}
+
+	var operr *net.OpError
+	if errors.As(err, &operr) {
+		return true
+	}
+
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// Calls the request function until it succeeds, returns an error that is not
+// retryable, or the retries of the policy are exhausted. The last error is returned
+// as is so that callers can inspect it. The wait between retries is cut short if the
+// context is done, and no retry is attempted if it would exceed the context deadline.
+func (p RetryPolicy) do(ctx context.Context, request func() error) (err error) {
+	interval := p.InitialInterval
+	for attempt := 0; ; attempt++ {
+		if err = request(); err == nil || attempt >= p.MaxRetries || !Retryable(err) {
+			return err
+		}
+
+		wait := p.jitter(interval)
+		var httperr HTTPError
+		if errors.As(err, &httperr) && httperr.RetryAfter > wait {
+			if httperr.RetryAfter > p.MaxInterval {
+				return err
+			}
+			wait = httperr.RetryAfter
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+
+		if interval = time.Duration(float64(interval) * math.Max(p.Multiplier, 1)); interval > p.MaxInterval {
+			interval = p.MaxInterval
+		}
+	}
+}
+
+// Randomizes the interval by plus or minus the jitter fraction of the interval.
+func (p RetryPolicy) jitter(interval time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return interval
+	}
+
+	delta := p.Jitter * float64(interval)
+	return time.Duration(float64(interval) - delta + rand.Float64()*2*delta)
+}
+
+// Parses the Retry-After header of a response, which may either be a number of seconds
+// or an HTTP date. Zero is returned if the header is missing or cannot be parsed.
+func retryAfter(header http.Header) time.Duration {
+	value := header.Get(HeaderRetryAfter)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
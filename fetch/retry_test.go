@@ -0,0 +1,92 @@
+package fetch_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rotationalio/baleen/fetch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	testCases := []struct {
+		name       string
+		failures   []int
+		retryAfter string
+		attempts   int32
+		code       int
+	}{
+		{"unavailable", []int{http.StatusServiceUnavailable, http.StatusBadGateway}, "", 3, 0},
+		{"too many requests", []int{http.StatusTooManyRequests}, "", 2, 0},
+		{"exhausted", []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError}, "", 4, http.StatusInternalServerError},
+		{"not found", []int{http.StatusNotFound}, "", 1, http.StatusNotFound},
+		{"retry after too long", []int{http.StatusTooManyRequests}, "120", 1, http.StatusTooManyRequests},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var attempts int32
+			url := NewServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/robots.txt" {
+					http.NotFound(w, r)
+					return
+				}
+
+				if n := atomic.AddInt32(&attempts, 1); int(n) <= len(tc.failures) {
+					if tc.retryAfter != "" {
+						w.Header().Set(fetch.HeaderRetryAfter, tc.retryAfter)
+					}
+					w.WriteHeader(tc.failures[n-1])
+					return
+				}
+
+				w.Header().Set(fetch.HeaderContentType, "text/html; charset=utf-8")
+				fmt.Fprint(w, "<html><head><title>Hello World</title></head><body></body></html>")
+			})
+			fetch.SetRetryPolicy(fetch.RetryPolicy{MaxRetries: 3, InitialInterval: 5 * time.Millisecond, MaxInterval: 50 * time.Millisecond, Multiplier: 2, Jitter: 0.2})
+
+			html, err := fetch.NewHTMLFetcher(url + "/post").Fetch(context.Background())
+			require.Equal(t, tc.attempts, atomic.LoadInt32(&attempts), "unexpected number of attempts")
+
+			if tc.code == 0 {
+				require.NoError(t, err)
+				require.Equal(t, "Hello World", html.Title())
+				return
+			}
+
+			var httperr fetch.HTTPError
+			require.True(t, errors.As(err, &httperr), "expected an http error")
+			require.Equal(t, tc.code, httperr.Code)
+			if tc.retryAfter != "" {
+				require.Equal(t, 120*time.Second, httperr.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	require.False(t, fetch.Retryable(nil))
+	require.True(t, fetch.Retryable(fetch.HTTPError{Code: http.StatusServiceUnavailable}))
+	require.True(t, fetch.Retryable(fetch.HTTPError{Code: http.StatusTooManyRequests}))
+	require.False(t, fetch.Retryable(fetch.HTTPError{Code: http.StatusNotFound}))
+	require.False(t, fetch.Retryable(fetch.HTTPError{Code: http.StatusNotModified}))
+	require.False(t, fetch.Retryable(fetch.ErrDisallowed))
+	require.False(t, fetch.Retryable(context.DeadlineExceeded))
+	require.False(t, fetch.Retryable(errors.New("could not parse feed")))
+
+	// Network errors are retryable, e.g. if the connection is refused
+	server := httptest.NewServer(http.NotFoundHandler())
+	fetch.SetClient(server.Client())
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+	server.Close()
+
+	_, err := fetch.NewHTMLFetcher(server.URL).Fetch(context.Background())
+	require.Error(t, err)
+	require.True(t, fetch.Retryable(err), "expected network error to be retryable")
+}
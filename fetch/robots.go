@@ -0,0 +1,254 @@
+package fetch
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Constants for fetching and caching robots.txt files as described by RFC 9309.
+const (
+	robotsPath     = "/robots.txt"
+	robotsAgent    = "baleen"         // the product token of the user agent matched in robots.txt
+	robotsTTL      = 24 * time.Hour   // how long a robots.txt file is cached
+	robotsErrorTTL = 10 * time.Minute // how long a robots.txt with a server error is cached
+	robotsMaxSize  = 500 * 1024       // the maximum number of bytes of robots.txt parsed
+)
+
+// A package level cache of the robots.txt rules of each host that is consulted by all
+// of the http based fetchers in this package before making a request.
+var robots = &robotsCache{hosts: make(map[string]*robotsHost)}
+
+// Caches the robots.txt rules for each host, keyed by scheme and host.
+type robotsCache struct {
+	sync.Mutex
+	hosts map[string]*robotsHost
+}
+
+// The robots.txt rules of a host along with when they expire.
+type robotsHost struct {
+	sync.Mutex
+	rules   *robotsRules
+	expires time.Time
+}
+
+// The rules of the robots.txt group that applies to the Baleen user agent.
+type robotsRules struct {
+	rules []robotsRule
+	delay time.Duration
+}
+
+type robotsRule struct {
+	allow   bool
+	length  int
+	pattern *regexp.Regexp
+}
+
+// Allow all or disallow all rules used when the robots.txt file cannot be parsed.
+var (
+	allowAll    = &robotsRules{}
+	disallowAll = &robotsRules{rules: []robotsRule{{allow: false, length: 1, pattern: regexp.MustCompile("^/")}}}
+)
+
+// check returns ErrDisallowed if the robots.txt of the host does not allow Baleen to
+// fetch the url, fetching and caching the robots.txt if necessary. Otherwise it returns
+// the Crawl-delay of the host, which is zero if the robots.txt does not specify one.
+func (c *robotsCache) check(ctx context.Context, u *url.URL) (_ time.Duration, err error) {
+	host := c.host(u)
+	host.Lock()
+	defer host.Unlock()
+
+	if host.rules == nil || time.Now().After(host.expires) {
+		// The robots.txt request is subject to the rate limit of the host
+		if err = limiter.wait(ctx, u, 0); err != nil {
+			return 0, err
+		}
+
+		// If the request fails the robots.txt is not cached so the error can be retried
+		if host.rules, host.expires, err = fetchRobots(ctx, u); err != nil {
+			return 0, err
+		}
+	}
+
+	if !host.rules.allowed(u) {
+		return 0, fmt.Errorf("%w: %s", ErrDisallowed, u)
+	}
+	return host.rules.delay, nil
+}
+
+func (c *robotsCache) host(u *url.URL) *robotsHost {
+	c.Lock()
+	defer c.Unlock()
+
+	key := strings.ToLower(u.Scheme + "://" + u.Host)
+	host, ok := c.hosts[key]
+	if !ok {
+		host = &robotsHost{}
+		c.hosts[key] = host
+	}
+	return host
+}
+
+// Fetches the robots.txt for the host of the url, returning the rules that apply to
+// Baleen and when they expire. If the robots.txt is unavailable (4xx) then all urls
+// are allowed, if the server cannot handle the request (5xx) then all urls are
+// disallowed until it is fetched again. An error is returned if the request fails.
+func fetchRobots(ctx context.Context, u *url.URL) (_ *robotsRules, _ time.Time, err error) {
+	target := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: robotsPath}
+
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil); err != nil {
+		return nil, time.Time{}, err
+	}
+	req.Header.Set(HeaderUserAgent, userAgent)
+
+	var rep *http.Response
+	if rep, err = client.Do(req); err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rep.Body.Close()
+
+	switch {
+	case rep.StatusCode >= 200 && rep.StatusCode < 300:
+		return parseRobots(io.LimitReader(rep.Body, robotsMaxSize)), time.Now().Add(robotsTTL), nil
+	case rep.StatusCode >= 400 && rep.StatusCode < 500:
+		return allowAll, time.Now().Add(robotsTTL), nil
+	default:
+		return disallowAll, time.Now().Add(robotsErrorTTL), nil
+	}
+}
+
+// Parses a robots.txt file and returns the rules of the group that applies to Baleen,
+// or the rules of the group that applies to all user agents if there is no group for
+// Baleen. Rules from multiple groups that apply to the same user agent are merged.
+func parseRobots(r io.Reader) *robotsRules {
+	var (
+		agents   []string
+		inRules  bool
+		matched  = &robotsRules{}
+		wildcard = &robotsRules{}
+		baleen   bool
+	)
+
+	// Returns the rules that the current group should be added to, if any.
+	groups := func() (rules []*robotsRules) {
+		for _, agent := range agents {
+			switch agent {
+			case robotsAgent:
+				rules = append(rules, matched)
+			case "*":
+				rules = append(rules, wildcard)
+			}
+		}
+		return rules
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				agents, inRules = nil, false
+			}
+
+			agent := strings.ToLower(value)
+			if i := strings.IndexByte(agent, '/'); i >= 0 {
+				agent = agent[:i]
+			}
+			agents = append(agents, agent)
+			baleen = baleen || agent == robotsAgent
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// An empty disallow allows everything and so is not a rule
+				continue
+			}
+
+			rule := robotsRule{allow: key == "allow", length: len(value), pattern: robotsPattern(value)}
+			for _, rules := range groups() {
+				rules.rules = append(rules.rules, rule)
+			}
+		case "crawl-delay":
+			inRules = true
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				for _, rules := range groups() {
+					rules.delay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	if baleen {
+		return matched
+	}
+	return wildcard
+}
+
+// Compiles a robots.txt path pattern into a regular expression, where * matches any
+// sequence of characters and a trailing $ matches the end of the url.
+func robotsPattern(value string) *regexp.Regexp {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+
+	parts := strings.Split(value, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	pattern := "^" + strings.Join(parts, ".*")
+	if anchored {
+		pattern += "$"
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// allowed returns true if the url may be fetched. The most specific (longest) rule that
+// matches the path of the url is used and allow rules are preferred if rules are tied.
+func (r *robotsRules) allowed(u *url.URL) bool {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+
+	if path == robotsPath {
+		return true
+	}
+
+	allowed, length := true, -1
+	for _, rule := range r.rules {
+		if !rule.pattern.MatchString(path) {
+			continue
+		}
+
+		if rule.length > length || (rule.length == length && rule.allow) {
+			allowed, length = rule.allow, rule.length
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,98 @@
+package fetch_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rotationalio/baleen/fetch"
+	"github.com/stretchr/testify/require"
+)
+
+const robotsTxt = `# Baleen may fetch everything except private posts and pdfs
+User-agent: *
+Disallow: /
+
+User-agent: Googlebot
+User-agent: Baleen/1.0
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+Crawl-delay: 0.1
+`
+
+func TestRobotsTxt(t *testing.T) {
+	url := NewServer(t, robotsHandler(http.StatusOK, robotsTxt))
+
+	testCases := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/posts/hello-world.html", true},
+		{"/private", false},
+		{"/private/secrets.html", false},
+		{"/private/public/hello.html", true},
+		{"/files/report.pdf", false},
+		{"/files/report.pdf?download=true", true},
+		{"/privacy.html", true},
+	}
+
+	for _, tc := range testCases {
+		_, err := fetch.NewHTMLFetcher(url + tc.path).Fetch(context.Background())
+		if tc.allowed {
+			require.NoError(t, err, "expected %s to be allowed", tc.path)
+		} else {
+			require.ErrorIs(t, err, fetch.ErrDisallowed, "expected %s to be disallowed", tc.path)
+		}
+	}
+
+	// The feed fetcher should also honor robots.txt
+	_, err := fetch.NewFeedFetcher(url + "/private/rss").Fetch(context.Background())
+	require.ErrorIs(t, err, fetch.ErrDisallowed)
+}
+
+func TestRobotsTxtCrawlDelay(t *testing.T) {
+	url := NewServer(t, robotsHandler(http.StatusOK, robotsTxt))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := fetch.NewHTMLFetcher(url + "/").Fetch(context.Background())
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond, "expected the crawl delay to be honored")
+
+	// The crawl delay should be cut short if the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := fetch.NewHTMLFetcher(url + "/").Fetch(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRobotsTxtUnavailable(t *testing.T) {
+	// All urls are allowed if there is no robots.txt
+	url := NewServer(t, robotsHandler(http.StatusNotFound, ""))
+	_, err := fetch.NewHTMLFetcher(url + "/private").Fetch(context.Background())
+	require.NoError(t, err)
+
+	// All urls are disallowed if the server cannot handle the robots.txt request
+	url = NewServer(t, robotsHandler(http.StatusServiceUnavailable, ""))
+	_, err = fetch.NewHTMLFetcher(url + "/").Fetch(context.Background())
+	require.ErrorIs(t, err, fetch.ErrDisallowed)
+}
+
+// Helper to serve a robots.txt with the specified status and an HTML page at any path.
+func robotsHandler(status int, robots string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.WriteHeader(status)
+			fmt.Fprint(w, robots)
+			return
+		}
+
+		w.Header().Set(fetch.HeaderContentType, "text/html; charset=utf-8")
+		fmt.Fprint(w, "<html><head><title>Hello World</title></head><body></body></html>")
+	}
+}
@@ -0,0 +1,328 @@
+package baleen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/rotationalio/baleen/config"
+	"github.com/segmentio/kafka-go"
+)
+
+// The kafka header used to preserve the watermill message UUID across the broker.
+const kafkaUUIDHeader = "_watermill_message_uuid"
+
+var ErrKafkaClosed = errors.New("kafka pub/sub is closed")
+
+// kafkaWriter and kafkaReader describe the subset of the kafka-go Writer and Reader
+// used by Baleen so that an in-process stand-in can be used instead of a broker.
+type kafkaWriter interface {
+	WriteMessages(context.Context, ...kafka.Message) error
+	Close() error
+}
+
+type kafkaReader interface {
+	FetchMessage(context.Context) (kafka.Message, error)
+	CommitMessages(context.Context, ...kafka.Message) error
+	Close() error
+}
+
+// Factories for the kafka writers and readers, these can be replaced in tests.
+var (
+	newKafkaWriter = func(conf config.KafkaConfig, balancer kafka.Balancer) kafkaWriter {
+		return &kafka.Writer{
+			Addr:                   kafka.TCP(kafkaBrokers(conf)...),
+			Balancer:               balancer,
+			AllowAutoTopicCreation: true,
+		}
+	}
+
+	newKafkaReader = func(conf config.KafkaConfig, topic string) kafkaReader {
+		return kafka.NewReader(kafka.ReaderConfig{
+			Brokers: kafkaBrokers(conf),
+			GroupID: conf.GroupID,
+			Topic:   topic,
+		})
+	}
+)
+
+// KafkaPublisher implements the watermill Publisher interface for a Kafka cluster.
+type KafkaPublisher struct {
+	sync.RWMutex
+	conf   config.KafkaConfig
+	writer kafkaWriter
+	logger watermill.LoggerAdapter
+	closed bool
+}
+
+var _ message.Publisher = &KafkaPublisher{}
+
+func CreateKafkaPublisher(conf config.KafkaConfig, logger watermill.LoggerAdapter) (message.Publisher, error) {
+	balancer, err := KafkaBalancer(conf.Balancer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KafkaPublisher{
+		conf:   conf,
+		writer: newKafkaWriter(conf, balancer),
+		logger: logger,
+	}, nil
+}
+
+// Publish the messages to the kafka topic mapped from the Baleen topic.
+func (p *KafkaPublisher) Publish(topic string, msgs ...*message.Message) error {
+	p.RLock()
+	defer p.RUnlock()
+	if p.closed {
+		return ErrKafkaClosed
+	}
+
+	topic = KafkaTopic(p.conf, topic)
+	kmsgs := make([]kafka.Message, 0, len(msgs))
+	for _, msg := range msgs {
+		kmsgs = append(kmsgs, marshalKafka(topic, msg))
+	}
+
+	if err := p.writer.WriteMessages(context.Background(), kmsgs...); err != nil {
+		return fmt.Errorf("could not publish to kafka topic %q: %w", topic, err)
+	}
+
+	p.logger.Trace("published messages to kafka", watermill.LogFields{"topic": topic, "num": len(msgs)})
+	return nil
+}
+
+func (p *KafkaPublisher) Close() error {
+	p.Lock()
+	defer p.Unlock()
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+	return p.writer.Close()
+}
+
+// KafkaSubscriber implements the watermill Subscriber interface for a Kafka cluster.
+// Each call to Subscribe creates a reader in the configured consumer group; messages
+// are committed to Kafka when they are acked and redelivered when they are nacked.
+// Members of a consumer group share the messages of a topic, so subscribers that must
+// each receive every message should be created in their own group with Group.
+type KafkaSubscriber struct {
+	sync.Mutex
+	conf    config.KafkaConfig
+	logger  watermill.LoggerAdapter
+	closing chan struct{}
+	readers sync.WaitGroup
+	closed  bool
+}
+
+var _ message.Subscriber = &KafkaSubscriber{}
+
+func CreateKafkaSubscriber(conf config.KafkaConfig, logger watermill.LoggerAdapter) (message.Subscriber, error) {
+	if _, err := KafkaBalancer(conf.Balancer); err != nil {
+		return nil, err
+	}
+
+	return &KafkaSubscriber{
+		conf:    conf,
+		logger:  logger,
+		closing: make(chan struct{}),
+	}, nil
+}
+
+// Group returns a new subscriber that consumes in a consumer group of its own, named
+// after the configured group and the specified name, e.g. the name of a handler.
+func (s *KafkaSubscriber) Group(name string) *KafkaSubscriber {
+	conf := s.conf
+	conf.GroupID = KafkaGroup(s.conf, name)
+	return &KafkaSubscriber{
+		conf:    conf,
+		logger:  s.logger,
+		closing: make(chan struct{}),
+	}
+}
+
+// Subscribe to the kafka topic mapped from the Baleen topic.
+func (s *KafkaSubscriber) Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.closed {
+		return nil, ErrKafkaClosed
+	}
+
+	topic = KafkaTopic(s.conf, topic)
+	reader := newKafkaReader(s.conf, topic)
+	out := make(chan *message.Message)
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-s.closing:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	s.readers.Add(1)
+	go func() {
+		defer s.readers.Done()
+		defer close(out)
+		defer cancel()
+		defer reader.Close()
+		s.consume(ctx, topic, reader, out)
+	}()
+
+	return out, nil
+}
+
+func (s *KafkaSubscriber) consume(ctx context.Context, topic string, reader kafkaReader, out chan<- *message.Message) {
+	logFields := watermill.LogFields{"topic": topic, "group": s.conf.GroupID}
+	for {
+		kmsg, err := reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+
+			s.logger.Error("could not fetch kafka message", err, logFields)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+				continue
+			}
+		}
+
+		msg := unmarshalKafka(kmsg)
+	deliver:
+		for {
+			msg.SetContext(ctx)
+			select {
+			case out <- msg:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case <-msg.Acked():
+				if err = reader.CommitMessages(ctx, kmsg); err != nil {
+					s.logger.Error("could not commit kafka message", err, logFields)
+				}
+				break deliver
+			case <-msg.Nacked():
+				// Wait before redelivering so that a failing handler does not spin on the
+				// message, then redeliver a copy since a message can only be acked once.
+				select {
+				case <-time.After(s.conf.NackResendSleep):
+				case <-ctx.Done():
+					return
+				}
+				msg = msg.Copy()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (s *KafkaSubscriber) Close() error {
+	s.Lock()
+	if s.closed {
+		s.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.closing)
+	s.Unlock()
+
+	s.readers.Wait()
+	return nil
+}
+
+// KafkaBalancer returns the kafka balancer for the name specified in the config.
+func KafkaBalancer(name string) (kafka.Balancer, error) {
+	switch name {
+	case "LeastBytes":
+		return &kafka.LeastBytes{}, nil
+	case "RoundRobin":
+		return &kafka.RoundRobin{}, nil
+	case "Hash":
+		return &kafka.Hash{}, nil
+	case "ReferenceHash":
+		return &kafka.ReferenceHash{}, nil
+	case "CRC32":
+		return kafka.CRC32Balancer{}, nil
+	case "Murmur2":
+		return kafka.Murmur2Balancer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown kafka balancer %q", name)
+	}
+}
+
+// KafkaGroup returns the name of the consumer group for the specified name, e.g. the
+// name of a handler, within the consumer group specified in the config.
+func KafkaGroup(conf config.KafkaConfig, name string) string {
+	return conf.GroupID + "." + name
+}
+
+// KafkaTopic maps a Baleen topic onto the Kafka topic name specified in the config.
+// Topics that are not configured are passed through unmodified.
+func KafkaTopic(conf config.KafkaConfig, topic string) string {
+	switch topic {
+	case TopicSubscriptions:
+		return conf.TopicSubscriptions
+	case TopicFeeds:
+		return conf.TopicFeeds
+	case TopicDocuments:
+		return conf.TopicDocuments
+	default:
+		return topic
+	}
+}
+
+// The kafka url may contain a comma separated list of brokers.
+func kafkaBrokers(conf config.KafkaConfig) []string {
+	brokers := strings.Split(conf.URL, ",")
+	for i, broker := range brokers {
+		brokers[i] = strings.TrimSpace(broker)
+	}
+	return brokers
+}
+
+func marshalKafka(topic string, msg *message.Message) kafka.Message {
+	headers := make([]kafka.Header, 0, len(msg.Metadata)+1)
+	headers = append(headers, kafka.Header{Key: kafkaUUIDHeader, Value: []byte(msg.UUID)})
+	for key, val := range msg.Metadata {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(val)})
+	}
+
+	return kafka.Message{
+		Topic:   topic,
+		Key:     []byte(msg.UUID),
+		Value:   msg.Payload,
+		Headers: headers,
+	}
+}
+
+func unmarshalKafka(kmsg kafka.Message) *message.Message {
+	var uuid string
+	metadata := make(message.Metadata, len(kmsg.Headers))
+	for _, header := range kmsg.Headers {
+		if header.Key == kafkaUUIDHeader {
+			uuid = string(header.Value)
+			continue
+		}
+		metadata.Set(header.Key, string(header.Value))
+	}
+
+	msg := message.NewMessage(uuid, kmsg.Value)
+	msg.Metadata = metadata
+	return msg
+}
@@ -0,0 +1,283 @@
+package baleen
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/events"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaPubSub(t *testing.T) {
+	broker := useKafkaBroker(t)
+	conf := config.KafkaConfig{
+		Enabled:            true,
+		URL:                "localhost:9092",
+		Balancer:           "RoundRobin",
+		GroupID:            "baleen",
+		TopicSubscriptions: "baleen-subscriptions",
+		TopicDocuments:     "baleen-documents",
+		TopicFeeds:         "baleen-feeds",
+		NackResendSleep:    50 * time.Millisecond,
+	}
+
+	publisher, err := CreateKafkaPublisher(conf, watermill.NopLogger{})
+	require.NoError(t, err, "could not create kafka publisher")
+	defer publisher.Close()
+	require.IsType(t, &kafka.RoundRobin{}, broker.balancer, "expected the writer to use the configured balancer")
+
+	subscriber, err := CreateKafkaSubscriber(conf, watermill.NopLogger{})
+	require.NoError(t, err, "could not create kafka subscriber")
+	defer subscriber.Close()
+
+	// Publish a subscription event which should be mapped onto the configured topic
+	sub := &events.Subscription{FeedID: "example", FeedURL: "https://example.com/rss"}
+	msg, err := events.Marshal(sub, watermill.NewULID())
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(TopicSubscriptions, msg))
+	require.Len(t, broker.topic("baleen-subscriptions"), 1)
+	require.Len(t, broker.topic(TopicSubscriptions), 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	C, err := subscriber.Subscribe(ctx, TopicSubscriptions)
+	require.NoError(t, err, "could not subscribe to kafka topic")
+
+	// A nacked message should be redelivered without being committed after a delay
+	rep := <-C
+	require.Equal(t, msg.UUID, rep.UUID)
+	require.Equal(t, msg.Metadata, rep.Metadata)
+	nacked := time.Now()
+	rep.Nack()
+	require.Equal(t, 0, broker.committed("baleen-subscriptions"))
+
+	// An acked message should be committed to the broker
+	rep = <-C
+	require.GreaterOrEqual(t, time.Since(nacked), conf.NackResendSleep, "expected the nacked message to be redelivered after the resend sleep")
+	require.Equal(t, msg.UUID, rep.UUID)
+	cmp, err := events.UnmarshalSubscription(rep)
+	require.NoError(t, err, "could not unmarshal redelivered message")
+	require.Equal(t, sub, cmp)
+	rep.Ack()
+
+	require.Eventually(t, func() bool {
+		return broker.committed("baleen-subscriptions") == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Closing the subscriber should close the message channel
+	require.NoError(t, subscriber.Close())
+	_, ok := <-C
+	require.False(t, ok, "expected the subscription channel to be closed")
+
+	// Cannot publish after the publisher is closed
+	require.NoError(t, publisher.Close())
+	require.ErrorIs(t, publisher.Publish(TopicFeeds, msg), ErrKafkaClosed)
+}
+
+func TestKafkaGroups(t *testing.T) {
+	broker := useKafkaBroker(t)
+	conf := config.KafkaConfig{
+		Enabled:        true,
+		URL:            "localhost:9092",
+		Balancer:       "LeastBytes",
+		GroupID:        "baleen",
+		TopicDocuments: "documents",
+	}
+
+	publisher, err := CreateKafkaPublisher(conf, watermill.NopLogger{})
+	require.NoError(t, err, "could not create kafka publisher")
+	defer publisher.Close()
+	require.IsType(t, &kafka.LeastBytes{}, broker.balancer, "expected the writer to use the configured balancer")
+
+	subscriber, err := CreateKafkaSubscriber(conf, watermill.NopLogger{})
+	require.NoError(t, err, "could not create kafka subscriber")
+	defer subscriber.Close()
+
+	// Each handler and the debug tap should consume the documents in their own group
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	groups := []string{HandlerS3Sink, HandlerFileSink, GroupDebug}
+	channels := make([]<-chan *message.Message, 0, len(groups))
+	for _, group := range groups {
+		sub := SubscriberGroup(subscriber, group)
+		defer sub.Close()
+
+		C, err := sub.Subscribe(ctx, TopicDocuments)
+		require.NoError(t, err, "could not subscribe to kafka topic")
+		channels = append(channels, C)
+	}
+	require.Equal(t, []string{"baleen.s3_sink", "baleen.file_sink", "baleen.debug"}, broker.groups)
+
+	msg := message.NewMessage(watermill.NewULID(), []byte("document"))
+	require.NoError(t, publisher.Publish(TopicDocuments, msg))
+
+	for i, C := range channels {
+		select {
+		case rep := <-C:
+			require.Equal(t, msg.UUID, rep.UUID, "expected group %q to receive the message", groups[i])
+			rep.Ack()
+		case <-ctx.Done():
+			t.Fatalf("group %q did not receive the message", groups[i])
+		}
+	}
+
+	// Other subscribers are not grouped
+	gochan := CreateGoChannel(config.GoChannelConfig{}, watermill.NopLogger{})
+	defer gochan.Close()
+	require.Same(t, gochan, SubscriberGroup(gochan, GroupDebug))
+}
+
+func TestKafkaConfig(t *testing.T) {
+	conf := config.KafkaConfig{
+		TopicSubscriptions: "a",
+		TopicFeeds:         "b",
+		TopicDocuments:     "c",
+	}
+
+	require.Equal(t, "a", KafkaTopic(conf, TopicSubscriptions))
+	require.Equal(t, "b", KafkaTopic(conf, TopicFeeds))
+	require.Equal(t, "c", KafkaTopic(conf, TopicDocuments))
+	require.Equal(t, "other", KafkaTopic(conf, "other"))
+
+	conf.GroupID = "baleen"
+	require.Equal(t, "baleen.feed_sync", KafkaGroup(conf, HandlerFeedSync))
+
+	for _, name := range []string{"LeastBytes", "RoundRobin", "Hash", "ReferenceHash", "CRC32", "Murmur2"} {
+		balancer, err := KafkaBalancer(name)
+		require.NoError(t, err, "could not create balancer %q", name)
+		require.NotNil(t, balancer)
+	}
+
+	_, err := KafkaBalancer("Random")
+	require.Error(t, err, "expected unknown balancer to error")
+
+	conf.URL = "kafka1:9092, kafka2:9092"
+	require.Equal(t, []string{"kafka1:9092", "kafka2:9092"}, kafkaBrokers(conf))
+}
+
+// useKafkaBroker replaces the kafka writer and reader factories with an in-process
+// broker for the duration of the test.
+func useKafkaBroker(t *testing.T) *memoryBroker {
+	broker := &memoryBroker{
+		topics:  make(map[string][]kafka.Message),
+		commits: make(map[string]int),
+		notify:  make(chan struct{}),
+	}
+
+	prevWriter, prevReader := newKafkaWriter, newKafkaReader
+	newKafkaWriter = func(_ config.KafkaConfig, balancer kafka.Balancer) kafkaWriter {
+		broker.Lock()
+		defer broker.Unlock()
+		broker.balancer = balancer
+		return broker
+	}
+	newKafkaReader = func(conf config.KafkaConfig, topic string) kafkaReader {
+		broker.Lock()
+		defer broker.Unlock()
+		broker.groups = append(broker.groups, conf.GroupID)
+		return &memoryReader{broker: broker, topic: topic}
+	}
+
+	t.Cleanup(func() {
+		newKafkaWriter, newKafkaReader = prevWriter, prevReader
+	})
+	return broker
+}
+
+// memoryBroker is a minimal in-process stand-in for a Kafka cluster with a single
+// partition per topic; every reader consumes the topic from the start. The balancer of
+// the writer and the consumer groups of the readers are recorded for the tests.
+type memoryBroker struct {
+	sync.Mutex
+	topics   map[string][]kafka.Message
+	commits  map[string]int
+	notify   chan struct{}
+	balancer kafka.Balancer
+	groups   []string
+}
+
+func (b *memoryBroker) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	b.Lock()
+	defer b.Unlock()
+	for _, msg := range msgs {
+		msg.Offset = int64(len(b.topics[msg.Topic]))
+		b.topics[msg.Topic] = append(b.topics[msg.Topic], msg)
+	}
+
+	// Wake up any readers waiting for messages
+	close(b.notify)
+	b.notify = make(chan struct{})
+	return nil
+}
+
+func (b *memoryBroker) Close() error {
+	return nil
+}
+
+func (b *memoryBroker) topic(name string) []kafka.Message {
+	b.Lock()
+	defer b.Unlock()
+	return b.topics[name]
+}
+
+func (b *memoryBroker) committed(topic string) int {
+	b.Lock()
+	defer b.Unlock()
+	return b.commits[topic]
+}
+
+type memoryReader struct {
+	broker *memoryBroker
+	topic  string
+	offset int
+	closed bool
+}
+
+func (r *memoryReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	for {
+		r.broker.Lock()
+		if r.closed {
+			r.broker.Unlock()
+			return kafka.Message{}, io.EOF
+		}
+
+		if msgs := r.broker.topics[r.topic]; r.offset < len(msgs) {
+			msg := msgs[r.offset]
+			r.offset++
+			r.broker.Unlock()
+			return msg, nil
+		}
+
+		notify := r.broker.notify
+		r.broker.Unlock()
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return kafka.Message{}, ctx.Err()
+		}
+	}
+}
+
+func (r *memoryReader) CommitMessages(_ context.Context, msgs ...kafka.Message) error {
+	r.broker.Lock()
+	defer r.broker.Unlock()
+	r.broker.commits[r.topic] += len(msgs)
+	return nil
+}
+
+func (r *memoryReader) Close() error {
+	r.broker.Lock()
+	defer r.broker.Unlock()
+	r.closed = true
+	return nil
+}
@@ -0,0 +1,309 @@
+/*
+Package langid implements offline language identification of text so that documents can
+be partitioned by language even if they do not declare a language or declare the wrong
+one. Languages written in a script used by only one supported language (e.g. Korean or
+Greek) are identified by script alone; languages sharing a script (e.g. the languages
+written in the Latin or Cyrillic alphabets) are identified by comparing the n-gram
+profile of the text with profiles built from an embedded corpus of each language using
+the rank order statistics described by Cavnar and Trenkle (1994).
+
+Basic Usage:
+
+	code, confidence := langid.Detect("Tous les êtres humains naissent libres")
+*/
+package langid
+
+import (
+	"embed"
+	"io/fs"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	// The maximum length of the n-grams in a profile.
+	maxGram = 3
+
+	// The number of the most frequent n-grams kept in a language profile. The rank of
+	// n-grams that are not in the profile is penalized as though it were this rank.
+	profileSize = 400
+
+	// The number of the most frequent n-grams of the text compared to the profiles.
+	textProfileSize = 300
+
+	// Text with fewer letters than this cannot be reliably identified.
+	minLetters = 12
+
+	// Only the first letters of long text are used to identify the language.
+	maxLetters = 8192
+
+	// The confidence is a softmax over the distances of the languages, where each
+	// difference of this distance (two n-grams missing from the profile) makes a
+	// language e times less likely than the closest language.
+	temperature = 2 * profileSize
+)
+
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+// Languages identified by the script they are written in rather than by n-grams.
+var scriptLanguages = []struct {
+	code   string
+	tables []*unicode.RangeTable
+}{
+	{"ja", []*unicode.RangeTable{unicode.Hiragana, unicode.Katakana}},
+	{"ko", []*unicode.RangeTable{unicode.Hangul}},
+	{"zh", []*unicode.RangeTable{unicode.Han}},
+	{"ar", []*unicode.RangeTable{unicode.Arabic}},
+	{"he", []*unicode.RangeTable{unicode.Hebrew}},
+	{"el", []*unicode.RangeTable{unicode.Greek}},
+	{"th", []*unicode.RangeTable{unicode.Thai}},
+	{"hi", []*unicode.RangeTable{unicode.Devanagari}},
+}
+
+// Scripts shared by multiple languages that are identified by their n-gram profiles.
+var scripts = map[string]*unicode.RangeTable{
+	"Latin":    unicode.Latin,
+	"Cyrillic": unicode.Cyrillic,
+}
+
+var (
+	profiles []*profile
+	setup    sync.Once
+)
+
+// A profile ranks the most frequent n-grams of a language's training corpus.
+type profile struct {
+	code   string
+	script string
+	ranks  map[string]int
+}
+
+// Detect identifies the language of the text, returning its ISO 639-1 code and a
+// confidence between 0 and 1. If the language cannot be identified (e.g. because the
+// text is too short) then an empty code and zero confidence are returned.
+func Detect(text string) (code string, confidence float64) {
+	setup.Do(loadProfiles)
+
+	letters, counts := scriptCounts(text)
+	if letters < minLetters {
+		return "", 0
+	}
+
+	// Japanese is written with a mix of kana and Han characters, so check the kana
+	// before identifying Chinese by the Han characters.
+	for _, lang := range scriptLanguages {
+		var n int
+		for _, table := range lang.tables {
+			n += counts[table]
+		}
+
+		if lang.code == "ja" && n > 0 && float64(n)/float64(n+counts[unicode.Han]) >= 0.1 {
+			return lang.code, float64(n+counts[unicode.Han]) / float64(letters)
+		}
+
+		if float64(n)/float64(letters) >= 0.5 {
+			return lang.code, float64(n) / float64(letters)
+		}
+	}
+
+	// Otherwise identify the language by the n-grams of the dominant script
+	script, n := dominantScript(counts)
+	if script == "" || float64(n)/float64(letters) < 0.5 {
+		return "", 0
+	}
+	return closest(newProfile("", script, text, textProfileSize))
+}
+
+// Languages returns the ISO 639-1 codes of all of the languages that can be identified.
+func Languages() []string {
+	setup.Do(loadProfiles)
+
+	codes := make([]string, 0, len(scriptLanguages)+len(profiles))
+	for _, lang := range scriptLanguages {
+		codes = append(codes, lang.code)
+	}
+
+	for _, p := range profiles {
+		codes = append(codes, p.code)
+	}
+
+	sort.Strings(codes)
+	return codes
+}
+
+// Returns the language whose profile is the shortest out-of-place distance from the
+// text profile. The confidence is the softmax probability of the closest language over
+// the distances of all of the languages written in the script of the text; it is close
+// to 1 if the other languages are much further from the text and falls towards an even
+// split between the languages that are about as close as the closest language.
+func closest(text *profile) (code string, confidence float64) {
+	if len(text.ranks) == 0 {
+		return "", 0
+	}
+
+	best := -1
+	dists := make([]int, 0, len(profiles))
+	for _, p := range profiles {
+		if p.script != text.script {
+			continue
+		}
+
+		dist := distance(text, p)
+		if best < 0 || dist < best {
+			best = dist
+			code = p.code
+		}
+		dists = append(dists, dist)
+	}
+
+	if best < 0 {
+		return "", 0
+	}
+
+	// Relative to the closest language to avoid underflow for long text.
+	var total float64
+	for _, dist := range dists {
+		total += math.Exp(-float64(dist-best) / temperature)
+	}
+	return code, 1 / total
+}
+
+// Computes the out-of-place distance between the text and language profiles.
+func distance(text, lang *profile) (dist int) {
+	for gram, rank := range text.ranks {
+		if other, ok := lang.ranks[gram]; ok {
+			if rank > other {
+				dist += rank - other
+			} else {
+				dist += other - rank
+			}
+			continue
+		}
+		dist += profileSize
+	}
+	return dist
+}
+
+// Builds the language profiles from the embedded corpus, which is named by language.
+func loadProfiles() {
+	files, err := fs.Glob(corpus, "corpus/*.txt")
+	if err != nil {
+		panic(err)
+	}
+
+	profiles = make([]*profile, 0, len(files))
+	for _, file := range files {
+		var text []byte
+		if text, err = corpus.ReadFile(file); err != nil {
+			panic(err)
+		}
+
+		code := strings.TrimSuffix(path.Base(file), ".txt")
+		_, counts := scriptCounts(string(text))
+		script, _ := dominantScript(counts)
+		profiles = append(profiles, newProfile(code, script, string(text), profileSize))
+	}
+}
+
+// Creates a profile from the most frequent n-grams of the text.
+func newProfile(code, script, text string, size int) *profile {
+	freqs := make(map[string]int)
+	for _, word := range words(text) {
+		// Pad the words with spaces so that the n-grams capture prefixes and suffixes.
+		runes := []rune(" " + word + " ")
+		for n := 1; n <= maxGram; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				gram := string(runes[i : i+n])
+				if gram != " " {
+					freqs[gram]++
+				}
+			}
+		}
+	}
+
+	grams := make([]string, 0, len(freqs))
+	for gram := range freqs {
+		grams = append(grams, gram)
+	}
+
+	sort.Slice(grams, func(i, j int) bool {
+		if freqs[grams[i]] == freqs[grams[j]] {
+			return grams[i] < grams[j]
+		}
+		return freqs[grams[i]] > freqs[grams[j]]
+	})
+
+	if len(grams) > size {
+		grams = grams[:size]
+	}
+
+	p := &profile{code: code, script: script, ranks: make(map[string]int, len(grams))}
+	for rank, gram := range grams {
+		p.ranks[gram] = rank
+	}
+	return p
+}
+
+// Splits the text into lowercase words made up only of letters.
+func words(text string) []string {
+	// Truncate long text after the maximum number of letters
+	var letters int
+	for i, r := range text {
+		if unicode.IsLetter(r) {
+			if letters >= maxLetters {
+				text = text[:i]
+				break
+			}
+			letters++
+		}
+	}
+
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// Counts the number of letters in the text and the number of letters in each script.
+func scriptCounts(text string) (letters int, counts map[*unicode.RangeTable]int) {
+	counts = make(map[*unicode.RangeTable]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+
+		if letters >= maxLetters {
+			break
+		}
+		letters++
+
+		for _, lang := range scriptLanguages {
+			for _, table := range lang.tables {
+				if unicode.Is(table, r) {
+					counts[table]++
+				}
+			}
+		}
+
+		for _, table := range scripts {
+			if unicode.Is(table, r) {
+				counts[table]++
+			}
+		}
+	}
+	return letters, counts
+}
+
+// Returns the shared script with the most letters and the number of letters in it.
+func dominantScript(counts map[*unicode.RangeTable]int) (script string, n int) {
+	for name, table := range scripts {
+		if counts[table] > n || (counts[table] == n && n > 0 && name < script) {
+			script, n = name, counts[table]
+		}
+	}
+	return script, n
+}
@@ -0,0 +1,89 @@
+package langid_test
+
+import (
+	"testing"
+
+	"github.com/rotationalio/baleen/langid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		expected string
+		text     string
+	}{
+		{"en", "The weather forecast says that it will rain tomorrow afternoon, so we should bring an umbrella to the game."},
+		{"de", "Die Wettervorhersage sagt, dass es morgen Nachmittag regnen wird, deshalb sollten wir einen Regenschirm mitnehmen."},
+		{"fr", "La météo annonce de la pluie pour demain après-midi, alors nous devrions emporter un parapluie au match."},
+		{"es", "El pronóstico del tiempo dice que mañana por la tarde va a llover, así que deberíamos llevar un paraguas al partido."},
+		{"it", "Le previsioni del tempo dicono che domani pomeriggio pioverà, quindi dovremmo portare un ombrello alla partita."},
+		{"pt", "A previsão do tempo diz que vai chover amanhã à tarde, por isso devemos levar um guarda-chuva para o jogo."},
+		{"nl", "Volgens de weersverwachting gaat het morgenmiddag regenen, dus we moeten een paraplu meenemen naar de wedstrijd."},
+		{"sv", "Väderprognosen säger att det kommer att regna i morgon eftermiddag, så vi borde ta med ett paraply till matchen."},
+		{"pl", "Prognoza pogody mówi, że jutro po południu będzie padać, więc powinniśmy zabrać parasol na mecz."},
+		{"tr", "Hava durumu yarın öğleden sonra yağmur yağacağını söylüyor, bu yüzden maça bir şemsiye götürmeliyiz."},
+		{"fi", "Sääennusteen mukaan huomenna iltapäivällä sataa, joten meidän pitäisi ottaa sateenvarjo mukaan otteluun."},
+		{"ru", "Прогноз погоды обещает дождь завтра после обеда, поэтому нам стоит взять с собой зонтик на матч."},
+		{"uk", "Прогноз погоди обіцяє дощ завтра після обіду, тому нам варто взяти з собою парасольку на матч."},
+		{"ja", "天気予報によると、明日の午後は雨が降るそうなので、試合には傘を持っていきましょう。"},
+		{"zh", "天气预报说明天下午会下雨，所以我们应该带一把伞去看比赛。"},
+		{"ko", "일기 예보에 따르면 내일 오후에 비가 온다고 하니 경기에 우산을 가져가야 합니다."},
+		{"el", "Η πρόγνωση του καιρού λέει ότι αύριο το απόγευμα θα βρέξει, οπότε πρέπει να πάρουμε ομπρέλα."},
+		{"ar", "تقول النشرة الجوية إن المطر سيهطل غدا بعد الظهر، لذلك يجب أن نأخذ مظلة إلى المباراة."},
+	}
+
+	for _, tc := range testCases {
+		code, confidence := langid.Detect(tc.text)
+		require.Equal(t, tc.expected, code, "could not detect language of %q", tc.text)
+		require.Greater(t, confidence, 0.0, "expected a positive confidence for %q", tc.text)
+		require.LessOrEqual(t, confidence, 1.0, "expected confidence to be at most 1 for %q", tc.text)
+	}
+}
+
+func TestDetectUnknown(t *testing.T) {
+	for _, text := range []string{"", "hello", "12345 67890 !!! ???", "ok ok ok"} {
+		code, confidence := langid.Detect(text)
+		require.Empty(t, code, "expected no language for %q", text)
+		require.Zero(t, confidence)
+	}
+}
+
+func TestConfidence(t *testing.T) {
+	// Longer text should be identified with more confidence than short text
+	_, short := langid.Detect("Il pleut beaucoup.")
+	_, long := langid.Detect("Il pleut beaucoup depuis ce matin et les rues sont inondées, alors les enfants sont restés à la maison pour jouer avec leurs amis.")
+	require.Greater(t, long, short)
+
+	// Clearly monolingual text should be identified with high confidence, even if it is
+	// written in a language that is closely related to another supported language.
+	testCases := []struct {
+		expected string
+		text     string
+	}{
+		{"en", "The weather forecast says that it will rain tomorrow afternoon, so we should bring an umbrella to the game."},
+		{"de", "Die Wettervorhersage sagt, dass es morgen Nachmittag regnen wird, deshalb sollten wir einen Regenschirm mitnehmen."},
+		{"es", "El pronóstico del tiempo dice que mañana por la tarde va a llover, así que deberíamos llevar un paraguas al partido."},
+		{"pt", "A previsão do tempo diz que vai chover amanhã à tarde, por isso devemos levar um guarda-chuva para o jogo."},
+		{"nl", "Volgens de weersverwachting gaat het morgenmiddag regenen, dus we moeten een paraplu meenemen naar de wedstrijd."},
+		{"ru", "Прогноз погоды обещает дождь завтра после обеда, поэтому нам стоит взять с собой зонтик на матч."},
+		{"uk", "Прогноз погоди обіцяє дощ завтра після обіду, тому нам варто взяти з собою парасольку на матч."},
+	}
+
+	for _, tc := range testCases {
+		code, confidence := langid.Detect(tc.text)
+		require.Equal(t, tc.expected, code, "could not detect language of %q", tc.text)
+		require.GreaterOrEqual(t, confidence, 0.9, "expected high confidence for %q", tc.text)
+	}
+
+	// Text that mixes languages should not be identified with high confidence
+	_, mixed := langid.Detect("Der Hund läuft schnell. The dog runs fast and far away.")
+	require.Less(t, mixed, 0.9)
+}
+
+func TestLanguages(t *testing.T) {
+	languages := langid.Languages()
+	require.Contains(t, languages, "en")
+	require.Contains(t, languages, "ja")
+	require.Contains(t, languages, "uk")
+	require.IsIncreasing(t, languages)
+}
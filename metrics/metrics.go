@@ -17,21 +17,32 @@ const NamespaceBaleen = "baleen"
 
 var (
 	// Baleen specific collectors
-	Subscriptions prometheus.Gauge
-	FeedSyncs     *prometheus.CounterVec
-	FeedItems     *prometheus.CounterVec
-	Documents     *prometheus.CounterVec
+	Subscriptions       prometheus.Gauge
+	FeedSyncs           *prometheus.CounterVec
+	FeedSyncsUnmodified *prometheus.CounterVec
+	FeedsDeactivated    *prometheus.CounterVec
+	FeedItems           *prometheus.CounterVec
+	Documents           *prometheus.CounterVec
+	FetchLatency        *prometheus.HistogramVec
+	FetchSize           *prometheus.HistogramVec
 )
 
 // Internal package variables for serving the collectors to the Prometheus scraper.
 var (
-	srv   *http.Server
-	cfg   config.MonitoringConfig
-	setup sync.Once
-	mu    sync.Mutex
-	err   error
+	srv        *http.Server
+	cfg        config.MonitoringConfig
+	setup      sync.Once
+	mu         sync.Mutex
+	err        error
+	collectors []prometheus.Collector
 )
 
+// The collectors are initialized when the package is loaded so that they can be safely
+// used by the handlers even if the metrics server is not being served.
+func init() {
+	collectors = initCollectors()
+}
+
 func Serve(conf config.MonitoringConfig) error {
 	// Guard against concurrent Serve and Shutdown
 	mu.Lock()
@@ -110,13 +121,29 @@ func Shutdown(ctx context.Context) error {
 	return nil
 }
 
-// Initializes and registers the metric collectors in Prometheus. This function should
-// only be called once from the Serve function. All new metrics must be defined in this
-// function so that they can be used.
+// Registers the metric collectors in Prometheus. This function is called from the
+// Serve function; collectors that were registered by a previous Serve are skipped.
 func registerCollectors() (err error) {
-	// Track all collectors to make it easier to register them at the end of this
-	// function. When adding new collectors make sure to increase the capacity.
-	collectors := make([]prometheus.Collector, 0, 8)
+	for _, collector := range collectors {
+		if err = prometheus.Register(collector); err != nil {
+			if _, ok := err.(prometheus.AlreadyRegisteredError); ok {
+				continue
+			}
+
+			log.Debug().Err(err).Msg("could not register collector")
+			return err
+		}
+	}
+	return nil
+}
+
+// Initializes the metric collectors. This function should only be called once when
+// the package is loaded. All new metrics must be defined in this function so that they
+// can be used.
+func initCollectors() []prometheus.Collector {
+	// Track all collectors to make it easier to register them from Serve. When adding
+	// new collectors make sure to increase the capacity.
+	collectors := make([]prometheus.Collector, 0, 9)
 
 	// Baleen Collectors
 	Subscriptions = prometheus.NewGauge(prometheus.GaugeOpts{
@@ -133,10 +160,24 @@ func registerCollectors() (err error) {
 	}, []string{"node", "status_code"})
 	collectors = append(collectors, FeedSyncs)
 
+	FeedSyncsUnmodified = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NamespaceBaleen,
+		Name:      "feed_syncs_not_modified",
+		Help:      "the number of feed syncs where the feed was not modified since the last sync",
+	}, []string{"node"})
+	collectors = append(collectors, FeedSyncsUnmodified)
+
+	FeedsDeactivated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: NamespaceBaleen,
+		Name:      "feeds_deactivated",
+		Help:      "the number of feeds deactivated because they are gone or failed too many consecutive syncs",
+	}, []string{"node"})
+	collectors = append(collectors, FeedsDeactivated)
+
 	FeedItems = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Namespace: NamespaceBaleen,
 		Name:      "feed_items",
-		Help:      "the number of feed times discovered across all feed syncs",
+		Help:      "the number of feed items discovered across all feed syncs",
 	}, []string{"node", "feed_id"})
 	collectors = append(collectors, FeedItems)
 
@@ -147,13 +188,28 @@ func registerCollectors() (err error) {
 	}, []string{"node", "status_code"})
 	collectors = append(collectors, Documents)
 
-	// Register all the collectors
-	for _, collector := range collectors {
-		if err = prometheus.Register(collector); err != nil {
-			log.Debug().Err(err).Msg("could not register collector")
-			return err
-		}
-	}
+	FetchLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NamespaceBaleen,
+		Name:      "fetch_latency_seconds",
+		Help:      "the time taken to fetch a resource from the web by each handler",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 45},
+	}, []string{"node", "handler"})
+	collectors = append(collectors, FetchLatency)
 
-	return nil
+	FetchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: NamespaceBaleen,
+		Name:      "fetch_response_bytes",
+		Help:      "the size of the response bodies fetched from the web by each handler",
+		Buckets:   prometheus.ExponentialBuckets(1024, 4, 8),
+	}, []string{"node", "handler"})
+	collectors = append(collectors, FetchSize)
+
+	return collectors
+}
+
+// NodeID returns the node label of the metrics server for use in collector labels.
+func NodeID() string {
+	mu.Lock()
+	defer mu.Unlock()
+	return cfg.NodeID
 }
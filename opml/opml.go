@@ -8,12 +8,16 @@ between feed aggregation tools and sites.
 package opml
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // An OPML struct can be loaded from an OPML file using an XML processor.
@@ -26,24 +30,131 @@ type OPML struct {
 
 // The Head of the OPML data.
 type Head struct {
-	XMLName xml.Name `xml:"head" json:"-"`
-	Title   string   `xml:"title" json:"title"`
+	XMLName     xml.Name `xml:"head" json:"-"`
+	Title       string   `xml:"title" json:"title"`
+	DateCreated string   `xml:"dateCreated,omitempty" json:"dateCreated,omitempty"`
 }
 
 // The Body of the OPML data.
 type Body struct {
-	XMLName  xml.Name  `xml:"body" json:"-"`
-	Outlines []Outline `xml:"outline" json:"outline"`
+	XMLName  xml.Name `xml:"body" json:"-"`
+	Outlines Outlines `xml:"outline" json:"outline"`
 }
 
 // The Outline of the OPML data - this contains the primary content for the web feed.
+// Outlines may be nested, e.g. feed aggregators export feeds nested inside of folder
+// outlines that do not have urls of their own.
+//
+// Baleen also records the status of its subscriptions when it exports them; these
+// attributes are ignored by other feed aggregators.
 type Outline struct {
-	Text    string `xml:"text,attr" json:"_text"`
-	Title   string `xml:"title,attr" json:"_title"`
-	Type    string `xml:"type,attr" json:"_type"`
-	XMLURL  string `xml:"xmlUrl,attr" json:"_xmlUrl"`
-	HTMLURL string `xml:"htmlUrl,attr" json:"_htmlUrl"`
-	Favicon string `xml:"rssfr-favicon,attr" json:"-"`
+	Text     string   `xml:"text,attr" json:"_text"`
+	Title    string   `xml:"title,attr" json:"_title"`
+	Type     string   `xml:"type,attr,omitempty" json:"_type,omitempty"`
+	XMLURL   string   `xml:"xmlUrl,attr,omitempty" json:"_xmlUrl,omitempty"`
+	HTMLURL  string   `xml:"htmlUrl,attr,omitempty" json:"_htmlUrl,omitempty"`
+	Category string   `xml:"category,attr,omitempty" json:"_category,omitempty"`
+	Favicon  string   `xml:"rssfr-favicon,attr,omitempty" json:"-"`
+	Status   string   `xml:"status,attr,omitempty" json:"_status,omitempty"`
+	Error    string   `xml:"error,attr,omitempty" json:"_error,omitempty"`
+	Failures int      `xml:"failures,attr,omitempty" json:"_failures,omitempty"`
+	SyncedAt string   `xml:"syncedAt,attr,omitempty" json:"_syncedAt,omitempty"`
+	Outlines Outlines `xml:"outline" json:"outline,omitempty"`
+}
+
+// Outlines is a list of outline elements. When OPML is converted to JSON, an element
+// with a single child outline is usually converted to an object rather than an array,
+// so Outlines can be unmarshaled from either a JSON object or a JSON array.
+type Outlines []Outline
+
+// UnmarshalJSON handles both a single outline object and an array of outlines.
+func (o *Outlines) UnmarshalJSON(data []byte) (err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var outline Outline
+		if err = json.Unmarshal(data, &outline); err != nil {
+			return err
+		}
+		*o = Outlines{outline}
+		return nil
+	}
+
+	var outlines []Outline
+	if err = json.Unmarshal(data, &outlines); err != nil {
+		return err
+	}
+	*o = outlines
+	return nil
+}
+
+// Feed is a web feed outline along with the categories it was filed under, which
+// includes the names of the folder outlines the feed was nested in and the categories
+// specified by the category attribute of the outline.
+type Feed struct {
+	Text       string
+	Title      string
+	Type       string
+	XMLURL     string
+	HTMLURL    string
+	Categories []string
+	Status     string
+	Error      string
+	Failures   int
+	SyncedAt   time.Time
+}
+
+// New creates an OPML document with the specified title from a list of feeds. Feeds are
+// nested in a folder outline named by their first category (so that feed aggregators
+// that use folders import them in the same folder) and any other categories are
+// specified by the category attribute of the feed's outline.
+func New(title string, feeds []Feed) *OPML {
+	outline := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+
+	folders := make(map[string]int)
+	for _, feed := range feeds {
+		item := Outline{
+			Text:     feed.Text,
+			Title:    feed.Title,
+			Type:     feed.Type,
+			XMLURL:   feed.XMLURL,
+			HTMLURL:  feed.HTMLURL,
+			Status:   feed.Status,
+			Error:    feed.Error,
+			Failures: feed.Failures,
+		}
+
+		if item.Text == "" {
+			item.Text = feed.Title
+		}
+
+		if !feed.SyncedAt.IsZero() {
+			item.SyncedAt = feed.SyncedAt.UTC().Format(time.RFC3339)
+		}
+
+		if len(feed.Categories) == 0 {
+			outline.Body.Outlines = append(outline.Body.Outlines, item)
+			continue
+		}
+
+		if len(feed.Categories) > 1 {
+			item.Category = strings.Join(feed.Categories[1:], ",")
+		}
+
+		folder, ok := folders[feed.Categories[0]]
+		if !ok {
+			folder = len(outline.Body.Outlines)
+			folders[feed.Categories[0]] = folder
+			outline.Body.Outlines = append(outline.Body.Outlines, Outline{Text: feed.Categories[0], Title: feed.Categories[0]})
+		}
+		outline.Body.Outlines[folder].Outlines = append(outline.Body.Outlines[folder].Outlines, item)
+	}
+	return outline
 }
 
 // Load an outline from an XML file stored on disk.
@@ -77,6 +188,95 @@ func Load(path string) (outline *OPML, err error) {
 	return outline, nil
 }
 
+// Save the outline to a file on disk as XML or JSON depending on its extension; the
+// JSON is wrapped in the same manner as the JSON that is read by Load.
+func (o *OPML) Save(path string) (err error) {
+	var write func(io.Writer) error
+	switch ext := filepath.Ext(path); ext {
+	case ".opml", ".xml":
+		write = o.WriteXML
+	case ".json":
+		write = o.WriteJSON
+	default:
+		return fmt.Errorf("unknown extension %q", ext)
+	}
+
+	var f *os.File
+	if f, err = os.Create(path); err != nil {
+		return err
+	}
+
+	if err = write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteXML writes the outline as an indented XML document.
+func (o *OPML) WriteXML(w io.Writer) (err error) {
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "\t")
+	if err = encoder.Encode(o); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// WriteJSON writes the outline as indented JSON wrapped in an object with an opml key.
+func (o *OPML) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "    ")
+	return encoder.Encode(map[string]*OPML{"opml": o})
+}
+
+// Feeds walks the outline documents, including any nested folder outlines, and returns
+// every outline that has a url as a feed. Feeds nested inside of folders are categorized
+// by the titles of those folders from the outermost folder inward. This method can
+// optionally filter by type in the same manner as URLs.
+func (o *OPML) Feeds(types ...string) []Feed {
+	filter := typeFilter(types)
+	feeds := make([]Feed, 0, len(o.Body.Outlines))
+	walk(o.Body.Outlines, nil, func(outline Outline, folders []string) {
+		if filter != nil {
+			if _, ok := filter[outline.Type]; !ok {
+				return
+			}
+		}
+
+		feed := Feed{
+			Text:     outline.Text,
+			Title:    outline.Title,
+			Type:     outline.Type,
+			XMLURL:   outline.XMLURL,
+			HTMLURL:  outline.HTMLURL,
+			Status:   outline.Status,
+			Error:    outline.Error,
+			Failures: outline.Failures,
+		}
+
+		if outline.SyncedAt != "" {
+			feed.SyncedAt, _ = time.Parse(time.RFC3339, outline.SyncedAt)
+		}
+
+		feed.Categories = append(feed.Categories, folders...)
+		for _, category := range strings.Split(outline.Category, ",") {
+			// Categories may be slash-delimited paths, e.g. /Tech/News
+			if category = strings.Trim(strings.TrimSpace(category), "/"); category != "" {
+				feed.Categories = appendUnique(feed.Categories, category)
+			}
+		}
+		feeds = append(feeds, feed)
+	})
+	return feeds
+}
+
 // URLs implements Baleen's most common use for OPML: extracting all of the feed URLs
 // from the outline documents and returning it as a slice of URL strings. This method
 // can optionally filter by type. E.g. to specify only RSS use `o.URLs("rss")`, if no
@@ -85,30 +285,62 @@ func Load(path string) (outline *OPML, err error) {
 // When processing the outline URLs, this method will take the XMLURL first, and if it
 // is empty it will return the HTMLURL. If neither URL contains data it will be skipped.
 func (o *OPML) URLs(types ...string) []string {
-	var filter map[string]struct{}
-	if len(types) > 0 {
-		filter = make(map[string]struct{})
-		for _, t := range types {
-			filter[t] = struct{}{}
+	feeds := o.Feeds(types...)
+	urls := make([]string, 0, len(feeds))
+	for _, feed := range feeds {
+		switch {
+		case feed.XMLURL != "":
+			urls = append(urls, feed.XMLURL)
+		case feed.HTMLURL != "":
+			urls = append(urls, feed.HTMLURL)
 		}
 	}
 
-	urls := make([]string, 0, len(o.Body.Outlines))
-	for _, outline := range o.Body.Outlines {
-		// Filter the outline by the specified type
-		if filter != nil {
-			if _, ok := filter[outline.Type]; !ok {
-				continue
-			}
+	return urls
+}
+
+// Recursively visits every outline that has a url along with the titles of the folder
+// outlines that it is nested in. Folders are outlines with children but without urls.
+func walk(outlines Outlines, folders []string, visit func(Outline, []string)) {
+	for _, outline := range outlines {
+		if outline.XMLURL != "" || outline.HTMLURL != "" {
+			visit(outline, folders)
 		}
 
-		switch {
-		case outline.XMLURL != "":
-			urls = append(urls, outline.XMLURL)
-		case outline.HTMLURL != "":
-			urls = append(urls, outline.HTMLURL)
+		if len(outline.Outlines) > 0 {
+			folder := outline.Title
+			if folder == "" {
+				folder = outline.Text
+			}
+
+			// Copy the folders so that sibling outlines do not share the backing array
+			nested := make([]string, 0, len(folders)+1)
+			nested = append(nested, folders...)
+			if folder != "" {
+				nested = append(nested, folder)
+			}
+			walk(outline.Outlines, nested, visit)
 		}
 	}
+}
 
-	return urls
+func typeFilter(types []string) map[string]struct{} {
+	if len(types) == 0 {
+		return nil
+	}
+
+	filter := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		filter[t] = struct{}{}
+	}
+	return filter
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
 }
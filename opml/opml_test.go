@@ -4,6 +4,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rotationalio/baleen/opml"
 	"github.com/stretchr/testify/require"
@@ -43,3 +44,80 @@ func TestURLs(t *testing.T) {
 	urls = outline.URLs("foo", "bar", "baz")
 	require.Len(t, urls, 0, "should be able to filter out all urls")
 }
+
+func TestNestedFeeds(t *testing.T) {
+	paths := []string{
+		"testdata/nested.opml",
+		"testdata/nested.json",
+	}
+
+	expected := []opml.Feed{
+		{Text: "feed1.com", Title: "Feed 1", Type: "rss", XMLURL: "http://www.feed1.com/rss", HTMLURL: "http://www.feed1.com/"},
+		{Text: "feed2.com", Title: "Feed 2", Type: "rss", XMLURL: "http://www.feed2.com/rss", HTMLURL: "http://www.feed2.com/", Categories: []string{"News", "World", "Politics"}},
+		{Text: "feed3.com", Title: "Feed 3", Type: "atom", XMLURL: "http://www.feed3.com/atom", HTMLURL: "http://www.feed3.com/", Categories: []string{"News", "Tech"}},
+		{Text: "feed4.com", Title: "Feed 4", Type: "rss", XMLURL: "http://www.feed4.com/rss", HTMLURL: "http://www.feed4.com/", Categories: []string{"News", "Tech"}},
+	}
+
+	for _, path := range paths {
+		ext := strings.ToUpper(strings.TrimPrefix(filepath.Ext(path), "."))
+		t.Run(ext, func(t *testing.T) {
+			outline, err := opml.Load(path)
+			require.NoError(t, err, "could not load outline")
+			require.Len(t, outline.Body.Outlines, 3, "expected the top-level outlines to be parsed")
+			require.Equal(t, expected, outline.Feeds())
+
+			urls := outline.URLs("rss")
+			require.Equal(t, []string{"http://www.feed1.com/rss", "http://www.feed2.com/rss", "http://www.feed4.com/rss"}, urls)
+		})
+	}
+}
+
+func TestSingleOutlineJSON(t *testing.T) {
+	// Feeds exported to JSON are nested in a folder object rather than in an array
+	outline, err := opml.Load("../fixtures/dib-feeds.json")
+	require.NoError(t, err, "could not load fixture data")
+	require.Len(t, outline.Body.Outlines, 1, "expected a single folder outline")
+
+	feeds := outline.Feeds()
+	require.Len(t, feeds, 62, "expected the feeds in the folder to be returned")
+	for _, feed := range feeds {
+		require.NotEmpty(t, feed.XMLURL)
+		require.Equal(t, []string{"dib"}, feed.Categories)
+	}
+}
+
+func TestSave(t *testing.T) {
+	synced := time.Date(2023, 4, 12, 14, 32, 0, 0, time.UTC)
+	feeds := []opml.Feed{
+		{Text: "feed1.com", Title: "Feed 1", Type: "rss", XMLURL: "http://www.feed1.com/rss", HTMLURL: "http://www.feed1.com/", Status: "active", SyncedAt: synced},
+		{Text: "feed2.com", Title: "Feed 2", Type: "rss", XMLURL: "http://www.feed2.com/rss", Categories: []string{"News", "World"}, Status: "active"},
+		{Text: "feed3.com", Title: "Feed 3", Type: "atom", XMLURL: "http://www.feed3.com/atom", Categories: []string{"News"}, Status: "inactive", Error: "404 Not Found", Failures: 10},
+		{Text: "feed4.com", Title: "Feed 4", Type: "rss", XMLURL: "http://www.feed4.com/rss", Categories: []string{"Tech"}, Status: "active"},
+	}
+
+	outline := opml.New("Baleen Subscriptions", feeds)
+	require.Equal(t, "2.0", outline.Version)
+	require.NotEmpty(t, outline.Head.DateCreated)
+	require.Len(t, outline.Body.Outlines, 3, "expected feeds to be nested in folders by category")
+
+	for _, name := range []string{"feeds.opml", "feeds.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, outline.Save(path), "could not save outline")
+
+			loaded, err := opml.Load(path)
+			require.NoError(t, err, "could not load saved outline")
+			require.Equal(t, "Baleen Subscriptions", loaded.Head.Title)
+
+			// Feeds are returned with their categories in folder order
+			actual := loaded.Feeds()
+			require.Len(t, actual, len(feeds))
+			require.Equal(t, feeds[0], actual[0])
+			require.Equal(t, feeds[1], actual[1])
+			require.Equal(t, feeds[2], actual[2])
+			require.Equal(t, feeds[3], actual[3])
+		})
+	}
+
+	require.Error(t, outline.Save(filepath.Join(t.TempDir(), "feeds.txt")), "expected unknown extensions to error")
+}
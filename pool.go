@@ -0,0 +1,66 @@
+package baleen
+
+import (
+	"sync"
+)
+
+// HostLimiter limits the number of concurrent requests made to a single host so that
+// synchronizing many feeds in parallel does not overwhelm any one domain.
+type HostLimiter struct {
+	sync.Mutex
+	limit int
+	hosts map[string]chan struct{}
+}
+
+// NewHostLimiter creates a limiter that allows limit concurrent requests per host. If
+// the limit is less than 1 then only one request per host is allowed at a time.
+func NewHostLimiter(limit int) *HostLimiter {
+	if limit < 1 {
+		limit = 1
+	}
+
+	return &HostLimiter{
+		limit: limit,
+		hosts: make(map[string]chan struct{}),
+	}
+}
+
+// Acquire blocks until a request can be made to the host, returning a function that
+// must be called to release the host when the request is complete.
+func (l *HostLimiter) Acquire(host string) (release func()) {
+	l.Lock()
+	sem, ok := l.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.hosts[host] = sem
+	}
+	l.Unlock()
+
+	sem <- struct{}{}
+	return func() { <-sem }
+}
+
+// Reorders the feeds so that consecutive feeds are from different hosts wherever
+// possible, e.g. a, a, a, b, b, c becomes a, b, c, a, b, a.
+func interleaveHosts(feeds []*Feed) []*Feed {
+	hosts := make([]string, 0)
+	groups := make(map[string][]*Feed)
+	for _, feed := range feeds {
+		host := feed.Host()
+		if _, ok := groups[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		groups[host] = append(groups[host], feed)
+	}
+
+	out := make([]*Feed, 0, len(feeds))
+	for len(out) < len(feeds) {
+		for _, host := range hosts {
+			if group := groups[host]; len(group) > 0 {
+				out = append(out, group[0])
+				groups[host] = group[1:]
+			}
+		}
+	}
+	return out
+}
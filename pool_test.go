@@ -0,0 +1,66 @@
+package baleen
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rotationalio/baleen/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostLimiter(t *testing.T) {
+	limiter := NewHostLimiter(2)
+
+	var (
+		wg      sync.WaitGroup
+		current int32
+		maxSeen int32
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release := limiter.Acquire("example.com")
+			defer release()
+
+			n := atomic.AddInt32(&current, 1)
+			for {
+				prev := atomic.LoadInt32(&maxSeen)
+				if n <= prev || atomic.CompareAndSwapInt32(&maxSeen, prev, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+		}()
+	}
+
+	// Another host should not be blocked by the example.com requests
+	release := limiter.Acquire("other.com")
+	release()
+
+	wg.Wait()
+	require.Equal(t, int32(2), maxSeen, "expected at most 2 concurrent requests to the host")
+}
+
+func TestInterleaveHosts(t *testing.T) {
+	urls := []string{
+		"https://a.com/1", "https://a.com/2", "https://a.com/3",
+		"https://b.com/1", "https://b.com/2", "http://c.com/rss",
+	}
+
+	feeds := make([]*Feed, 0, len(urls))
+	for _, url := range urls {
+		feeds = append(feeds, &Feed{info: &events.Subscription{FeedURL: url}})
+	}
+
+	hosts := make([]string, 0, len(feeds))
+	for _, feed := range interleaveHosts(feeds) {
+		hosts = append(hosts, feed.Host())
+	}
+	require.Equal(t, []string{"a.com", "b.com", "c.com", "a.com", "b.com", "a.com"}, hosts)
+}
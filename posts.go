@@ -3,13 +3,20 @@ package baleen
 import (
 	"context"
 	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/rotationalio/baleen/config"
 	"github.com/rotationalio/baleen/events"
 	"github.com/rotationalio/baleen/fetch"
+	"github.com/rotationalio/baleen/langid"
+	"github.com/rotationalio/baleen/metrics"
 	mime "github.com/rotationalio/go-ensign/mimetype/v1beta1"
 	"github.com/rs/zerolog/log"
 )
@@ -21,12 +28,12 @@ func (s *Baleen) AddPostFetch(conf config.PostFetchConfig) error {
 
 	// Add the handler to handle messages from the subscriptions topic.
 	handler := s.router.AddHandler(
-		"post_fetch",
+		HandlerPostFetch,
 		TopicFeeds,
-		s.subscriber,
+		SubscriberGroup(s.subscriber, HandlerPostFetch),
 		TopicDocuments,
 		s.publisher,
-		PostFetch,
+		NewPostFetcher(conf).Handle,
 	)
 
 	// Filter the type of messages handled
@@ -37,7 +44,34 @@ func (s *Baleen) AddPostFetch(conf config.PostFetchConfig) error {
 	return nil
 }
 
-func PostFetch(msg *message.Message) (_ []*message.Message, err error) {
+// The content type of the content of feed items, which is decoded by the feed parser.
+const feedContentType = "text/html; charset=utf-8"
+
+// Matches the text that feeds commonly end an excerpt of a post with to link to the
+// full text of the post, e.g. an ellipsis or "Continue reading".
+var truncated = regexp.MustCompile(`(?i)(\.\.\.|…|\[\s*(\.\.\.|…)\s*\]|read more|continue reading|read the (full|rest of the) (post|article|story))\W*$`)
+
+// Matches the primary language subtag of a language tag.
+var primarySubtag = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// PostFetcher creates documents from the posts of feed items. By default the page that
+// is linked to by the feed item is fetched; if feed content is enabled and the content
+// of the feed item contains the full text of the post, the document is created from the
+// feed content instead and the page is not fetched.
+type PostFetcher struct {
+	conf config.PostFetchConfig
+}
+
+func NewPostFetcher(conf config.PostFetchConfig) *PostFetcher {
+	return &PostFetcher{conf: conf}
+}
+
+// PostFetch fetches the page of every feed item regardless of its content.
+func PostFetch(msg *message.Message) ([]*message.Message, error) {
+	return (&PostFetcher{}).Handle(msg)
+}
+
+func (p *PostFetcher) Handle(msg *message.Message) (_ []*message.Message, err error) {
 	var event *events.FeedItem
 	if event, err = events.UnmarshalFeedItem(msg); err != nil {
 		return nil, err
@@ -47,6 +81,54 @@ func PostFetch(msg *message.Message) (_ []*message.Message, err error) {
 		return nil, nil
 	}
 
+	if p.conf.FeedContent && event.Content != "" {
+		html := fetch.NewHTML([]byte(event.Content), feedContentType)
+		if p.fullContent(html) {
+			return p.fromFeed(event, html)
+		}
+	}
+	return p.fromPage(event)
+}
+
+// Returns true if the content of a feed item appears to be the full text of the post
+// rather than an excerpt, i.e. it is long enough and it does not end with a link to
+// the rest of the post.
+func (p *PostFetcher) fullContent(html *fetch.HTML) bool {
+	text := html.Text()
+	if utf8.RuneCountInString(text) < p.conf.MinContentLength {
+		return false
+	}
+	return !truncated.MatchString(text)
+}
+
+// Creates the document from the content of the feed item without fetching the post.
+func (p *PostFetcher) fromFeed(event *events.FeedItem, html *fetch.HTML) (_ []*message.Message, err error) {
+	log.Info().Str("feed_id", event.FeedID).Str("url", event.Link).Msg("using feed content for post")
+	metrics.Documents.WithLabelValues(metrics.NodeID(), statusFeed).Inc()
+
+	doc := &events.Document{
+		FetchedAt: time.Now(),
+		Active:    true,
+		FeedID:    event.FeedID,
+		Source:    events.SourceFeed,
+	}
+
+	if err = parseDocument(doc, html, event); err != nil {
+		return nil, err
+	}
+
+	// The feed content is a fragment without a head, so the title, description, and
+	// metadata of the document are taken from the feed item instead.
+	doc.Title = event.Title
+	doc.Description = event.Description
+	doc.Author = strings.Join(event.Authors, ", ")
+	doc.Keywords = event.Categories
+	doc.PublishedAt = event.PublishedAt
+	return marshalDocument(doc)
+}
+
+// Creates the document by fetching the post from the page linked to by the feed item.
+func (p *PostFetcher) fromPage(event *events.FeedItem) (_ []*message.Message, err error) {
 	log.Info().Str("feed_id", event.FeedID).Str("url", event.Link).Msg("fetching post")
 	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
 	defer cancel()
@@ -55,36 +137,138 @@ func PostFetch(msg *message.Message) (_ []*message.Message, err error) {
 		FetchedAt: time.Now(),
 		Active:    true,
 		FeedID:    event.FeedID,
+		Source:    events.SourcePage,
 	}
 
 	var html *fetch.HTML
 	fetcher := fetch.NewHTMLFetcher(event.Link)
-	if html, err = fetcher.Fetch(ctx); err != nil {
+	start := time.Now()
+	html, err = fetcher.Fetch(ctx)
+	metrics.FetchLatency.WithLabelValues(metrics.NodeID(), HandlerPostFetch).Observe(time.Since(start).Seconds())
+
+	if err != nil {
 		log.Warn().Err(err).Str("url", event.Link).Str("feed_id", event.FeedID).Msg("could not fetch post")
-		httperr, ok := err.(*fetch.HTTPError)
-		if !ok {
+
+		// If the post is disallowed by robots.txt pass a document event with the reason.
+		if errors.Is(err, fetch.ErrDisallowed) {
+			metrics.Documents.WithLabelValues(metrics.NodeID(), statusDisallowed).Inc()
+			doc.Active = false
+			doc.Disallowed = true
+			doc.Error = err.Error()
+			doc.Link = event.Link
+			return marshalDocument(doc)
+		}
+
+		var httperr fetch.HTTPError
+		if !errors.As(err, &httperr) {
+			metrics.Documents.WithLabelValues(metrics.NodeID(), statusError).Inc()
 			return nil, err
 		}
 
 		// If we receive an http error pass an document error event on.
+		metrics.Documents.WithLabelValues(metrics.NodeID(), strconv.Itoa(httperr.Code)).Inc()
 		doc.Active = false
 		doc.StatusCode = httperr.Code
 		doc.Error = httperr.Status
+		doc.Link = event.Link
+		return marshalDocument(doc)
+	}
+
+	metrics.FetchSize.WithLabelValues(metrics.NodeID(), HandlerPostFetch).Observe(float64(html.Size()))
+	metrics.Documents.WithLabelValues(metrics.NodeID(), strconv.Itoa(http.StatusOK)).Inc()
+
+	if err = parseDocument(doc, html, event); err != nil {
+		return nil, err
 	}
+	return marshalDocument(doc)
+}
 
+// Populates the document from the content of the post and the feed item it belongs to.
+func parseDocument(doc *events.Document, html *fetch.HTML, event *events.FeedItem) (err error) {
 	if doc.Content, err = html.Extract(); err != nil {
 		log.Warn().Err(err).Str("url", event.Link).Str("feed_id", event.FeedID).Msg("could not decode post")
-		return nil, err
+		return err
 	}
 
 	doc.Title = html.Title()
 	doc.Description = html.Description()
 	doc.Link = event.Link
 
+	// The content is transcoded to UTF-8 from the charset the post was encoded with
+	doc.Encoding = "utf-8"
+	doc.Charset = html.Charset()
+
+	// Extract the main content of the post without the boilerplate
+	article := html.Article()
+	doc.Text = article.Text
+	doc.Article = article.HTML
+
+	// Identify the language from the text since it is often undeclared or incorrect
+	text := doc.Text
+	if text == "" {
+		text = html.Text()
+	}
+	doc.DetectedLanguage, doc.LanguageConfidence = langid.Detect(text)
+
+	// Prefer the language declared by the post, falling back to the language of the feed
+	// and then to the detected language if the language was not declared at all.
+	if doc.Language = languageCode(html.Language()); doc.Language == "" {
+		if doc.Language = languageCode(event.Language); doc.Language == "" {
+			doc.Language = doc.DetectedLanguage
+		}
+	}
+
+	// Metadata from the head of the post, e.g. OpenGraph properties and JSON-LD
+	doc.Canonical = html.Canonical()
+	doc.SiteName = html.SiteName()
+	doc.Author = html.Author()
+	doc.Keywords = html.Keywords()
+	doc.PublishedAt = html.Published()
+	doc.ModifiedAt = html.Modified()
+	doc.OpenGraph = html.OpenGraph()
+	doc.TwitterCard = html.TwitterCard()
+	if ld := html.LinkedData(); ld != nil {
+		doc.LinkedData = ld.Raw
+	}
+
+	// Date the document by when it was published according to the feed or the post,
+	// falling back to when it was fetched.
+	published := doc.FetchedAt
+	switch {
+	case !event.PublishedAt.IsZero():
+		published = event.PublishedAt
+	case !doc.PublishedAt.IsZero():
+		published = doc.PublishedAt
+	}
+
+	published = published.UTC()
+	doc.Year = published.Year()
+	doc.Month = published.Month().String()
+	doc.Day = published.Day()
+	return nil
+}
+
+// Normalizes a language tag such as en-US to its lowercase primary language subtag so
+// that documents are partitioned by language rather than by regional variant. Since the
+// language code is used in the storage path of the document, an empty string is
+// returned if the primary subtag is not a two or three letter ISO 639 language code.
+func languageCode(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+
+	tag = strings.ToLower(tag)
+	if !primarySubtag.MatchString(tag) {
+		return ""
+	}
+	return tag
+}
+
+func marshalDocument(doc *events.Document) (_ []*message.Message, err error) {
 	var out *message.Message
 	if out, err = events.Marshal(doc, watermill.NewULID()); err != nil {
 		return nil, err
 	}
-
 	return []*message.Message{out}, nil
 }
@@ -0,0 +1,257 @@
+package baleen_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/rotationalio/baleen"
+	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/events"
+	"github.com/rotationalio/baleen/fetch"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostFetchDocument(t *testing.T) {
+	url := postServer(t)
+
+	testCases := []struct {
+		path     string
+		item     *events.FeedItem
+		language string
+		date     time.Time
+	}{
+		{
+			// The html lang attribute takes precedence over the header and feed
+			"/lang",
+			&events.FeedItem{Language: "fr", PublishedAt: time.Date(2023, time.June, 5, 12, 0, 0, 0, time.UTC)},
+			"de", time.Date(2023, time.June, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// The Content-Language header takes precedence over the feed
+			"/header",
+			&events.FeedItem{Language: "fr", PublishedAt: time.Date(2021, time.March, 14, 23, 0, 0, 0, time.UTC)},
+			"es", time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Falls back to the feed language and the fetch time
+			"/none",
+			&events.FeedItem{Language: "en-us"},
+			"en", time.Now().UTC(),
+		},
+		{
+			// Falls back to the published time of the post if the feed has none
+			"/meta",
+			&events.FeedItem{Language: "en"},
+			"en", time.Date(2023, time.June, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Falls back to the detected language if no language is declared
+			"/detect",
+			&events.FeedItem{PublishedAt: time.Date(2022, time.December, 31, 8, 0, 0, 0, time.UTC)},
+			"de", time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Invalid language codes are ignored in favor of the feed language
+			"/invalid",
+			&events.FeedItem{Language: "fr-FR", PublishedAt: time.Date(2022, time.December, 31, 8, 0, 0, 0, time.UTC)},
+			"fr", time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// Invalid language codes are ignored in favor of the detected language
+			"/invalid",
+			&events.FeedItem{Language: "../x", PublishedAt: time.Date(2022, time.December, 31, 8, 0, 0, 0, time.UTC)},
+			"de", time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc.item.FeedID = "feed1"
+		tc.item.Link = url + tc.path
+
+		msg, err := events.Marshal(tc.item, watermill.NewULID())
+		require.NoError(t, err)
+
+		out, err := baleen.PostFetch(msg)
+		require.NoError(t, err, "could not fetch post %s", tc.path)
+		require.Len(t, out, 1)
+
+		doc, err := events.UnmarshalDocument(out[0])
+		require.NoError(t, err)
+		require.True(t, doc.Active)
+		require.Equal(t, tc.language, doc.Language, "unexpected language for %s", tc.path)
+		require.Equal(t, tc.date.Year(), doc.Year)
+		require.Equal(t, tc.date.Month().String(), doc.Month)
+		require.Equal(t, tc.date.Day(), doc.Day)
+		require.Equal(t, "utf-8", doc.Encoding)
+		require.Equal(t, "utf-8", doc.Charset)
+
+		if tc.path == "/detect" {
+			require.Equal(t, "de", doc.DetectedLanguage)
+			require.Greater(t, doc.LanguageConfidence, 0.0)
+			require.Equal(t, "Die Wettervorhersage sagt, dass es morgen Nachmittag regnen wird, deshalb sollten wir einen Regenschirm mitnehmen.", doc.Text)
+			require.Equal(t, "<div><p>Die Wettervorhersage sagt, dass es morgen Nachmittag regnen wird, deshalb sollten wir einen Regenschirm mitnehmen.</p></div>", doc.Article)
+		}
+	}
+}
+
+func TestPostFetchFeedContent(t *testing.T) {
+	url := postServer(t)
+	handler := baleen.NewPostFetcher(config.PostFetchConfig{Enabled: true, FeedContent: true, MinContentLength: 200})
+
+	paragraph := "<p>The weather forecast says that it will rain tomorrow afternoon, so we should take an umbrella with us when we go for a walk in the park.</p>"
+	full := strings.Repeat(paragraph, 3)
+
+	testCases := []struct {
+		path    string
+		content string
+		source  string
+		title   string
+	}{
+		{
+			// Full content is used without fetching the post (which is missing)
+			"/missing", full, events.SourceFeed, "Feed Title",
+		},
+		{
+			// Content shorter than the minimum length is an excerpt so the post is fetched
+			"/none", paragraph, events.SourcePage, "Hello World",
+		},
+		{
+			// Content that links to the rest of the post is truncated so the post is fetched
+			"/none", full + `<p><a href="/none">Continue reading &hellip;</a></p>`, events.SourcePage, "Hello World",
+		},
+		{
+			// The post is fetched if the feed item has no content
+			"/none", "", events.SourcePage, "Hello World",
+		},
+	}
+
+	for i, tc := range testCases {
+		item := &events.FeedItem{
+			FeedID:      "feed1",
+			Title:       "Feed Title",
+			Content:     tc.content,
+			Link:        url + tc.path,
+			Language:    "en-US",
+			Authors:     []string{"Jane Doe"},
+			PublishedAt: time.Date(2023, time.June, 5, 12, 0, 0, 0, time.UTC),
+		}
+
+		msg, err := events.Marshal(item, watermill.NewULID())
+		require.NoError(t, err)
+
+		out, err := handler.Handle(msg)
+		require.NoError(t, err, "could not handle feed item in test case %d", i)
+		require.Len(t, out, 1)
+
+		doc, err := events.UnmarshalDocument(out[0])
+		require.NoError(t, err)
+		require.True(t, doc.Active, "expected active document in test case %d", i)
+		require.Equal(t, tc.source, doc.Source, "unexpected source in test case %d", i)
+		require.Equal(t, tc.title, doc.Title, "unexpected title in test case %d", i)
+		require.Equal(t, item.Link, doc.Link)
+		require.Equal(t, "en", doc.Language)
+		require.Equal(t, 2023, doc.Year)
+		require.Equal(t, "June", doc.Month)
+		require.Equal(t, 5, doc.Day)
+
+		if tc.source == events.SourceFeed {
+			require.Equal(t, []byte(full), doc.Content)
+			require.Equal(t, "Jane Doe", doc.Author)
+			require.Equal(t, item.PublishedAt, doc.PublishedAt.UTC())
+			require.Contains(t, doc.Text, "The weather forecast says that it will rain tomorrow afternoon")
+			require.Equal(t, "en", doc.DetectedLanguage)
+		}
+	}
+
+	// Feed content is ignored unless it is enabled
+	msg, err := events.Marshal(&events.FeedItem{FeedID: "feed1", Content: full, Link: url + "/none"}, watermill.NewULID())
+	require.NoError(t, err)
+
+	out, err := baleen.PostFetch(msg)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+
+	doc, err := events.UnmarshalDocument(out[0])
+	require.NoError(t, err)
+	require.Equal(t, events.SourcePage, doc.Source)
+	require.Equal(t, "Hello World", doc.Title)
+}
+
+func TestPostFetchHTTPError(t *testing.T) {
+	url := postServer(t)
+	msg, err := events.Marshal(&events.FeedItem{FeedID: "feed1", Link: url + "/missing"}, watermill.NewULID())
+	require.NoError(t, err)
+
+	out, err := baleen.PostFetch(msg)
+	require.NoError(t, err, "http errors should be published as document events")
+	require.Len(t, out, 1)
+
+	doc, err := events.UnmarshalDocument(out[0])
+	require.NoError(t, err)
+	require.False(t, doc.Active)
+	require.Equal(t, http.StatusNotFound, doc.StatusCode)
+	require.Empty(t, doc.Content)
+}
+
+func TestPostFetchDisallowed(t *testing.T) {
+	url := postServer(t)
+	msg, err := events.Marshal(&events.FeedItem{FeedID: "feed1", Link: url + "/private/post"}, watermill.NewULID())
+	require.NoError(t, err)
+
+	out, err := baleen.PostFetch(msg)
+	require.NoError(t, err, "disallowed posts should be published as document events")
+	require.Len(t, out, 1)
+
+	doc, err := events.UnmarshalDocument(out[0])
+	require.NoError(t, err)
+	require.False(t, doc.Active)
+	require.True(t, doc.Disallowed)
+	require.Zero(t, doc.StatusCode)
+	require.Contains(t, doc.Error, "robots.txt")
+}
+
+// Helper to serve HTML posts that declare their language in different ways.
+func postServer(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(fetch.HeaderContentType, "text/html; charset=utf-8")
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Header().Set(fetch.HeaderContentType, "text/plain")
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		case "/lang":
+			w.Header().Set(fetch.HeaderContentLanguage, "es")
+			fmt.Fprint(w, `<html lang="de-AT"><head><title>Hallo Welt</title></head><body></body></html>`)
+		case "/header":
+			w.Header().Set(fetch.HeaderContentLanguage, "es-MX")
+			fmt.Fprint(w, `<html><head><title>Hola Mundo</title></head><body></body></html>`)
+		case "/none":
+			fmt.Fprint(w, `<html><head><title>Hello World</title></head><body></body></html>`)
+		case "/meta":
+			fmt.Fprint(w, `<html><head><title>Hello World</title>`+
+				`<link rel="canonical" href="https://example.com/hello">`+
+				`<meta name="author" content="Jane Doe"><meta name="keywords" content="hello, world">`+
+				`<meta property="og:title" content="Hello World"><meta property="og:site_name" content="Example">`+
+				`<meta property="article:published_time" content="2023-06-05T22:00:00Z">`+
+				`<meta name="twitter:card" content="summary">`+
+				`<script type="application/ld+json">{"@type":"BlogPosting","headline":"Hello World"}</script>`+
+				`</head><body></body></html>`)
+		case "/invalid":
+			w.Header().Set(fetch.HeaderContentLanguage, "en/../..")
+			fmt.Fprint(w, `<html lang="../../../../tmp/x"><head><title>Wetter</title></head><body><p>Die Wettervorhersage sagt, dass es morgen Nachmittag regnen wird, deshalb sollten wir einen Regenschirm mitnehmen.</p></body></html>`)
+		case "/detect":
+			fmt.Fprint(w, `<html><head><title>Wetter</title></head><body><p>Die Wettervorhersage sagt, dass es morgen Nachmittag regnen wird, deshalb sollten wir einen Regenschirm mitnehmen.</p></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+	return server.URL
+}
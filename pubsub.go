@@ -5,6 +5,7 @@ import (
 
 	"github.com/ThreeDotsLabs/watermill"
 	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/ThreeDotsLabs/watermill/pubsub/gochannel"
 	"github.com/rotationalio/baleen/config"
 	esdk "github.com/rotationalio/go-ensign"
 	"github.com/rotationalio/watermill-ensign/pkg/ensign"
@@ -17,7 +18,23 @@ const (
 	TopicDocuments     = "documents"
 )
 
+// Names of the router handlers, also used to label the metrics of each handler
+const (
+	HandlerFeedSync  = "feed_sync"
+	HandlerPostFetch = "post_fetch"
+	HandlerS3Sink    = "s3_sink"
+	HandlerFileSink  = "file_sink"
+)
+
+// Name of the subscriber group used to tap into the topics for debugging, so that the
+// debug subscribers do not take messages from the handlers.
+const GroupDebug = "debug"
+
 func CreatePublisher(conf config.PublisherConfig, logger watermill.LoggerAdapter) (message.Publisher, error) {
+	if conf.GoChannel.Enabled {
+		return CreateGoChannel(conf.GoChannel, logger), nil
+	}
+
 	if conf.Ensign.Enabled {
 		return CreateEnsignPublisher(conf.Ensign, logger)
 	}
@@ -42,11 +59,11 @@ func CreateEnsignPublisher(conf config.EnsignConfig, logger watermill.LoggerAdap
 	return ensign.NewPublisher(opts, logger)
 }
 
-func CreateKafkaPublisher(conf config.KafkaConfig, logger watermill.LoggerAdapter) (message.Publisher, error) {
-	return nil, errors.New("not implemented yet")
-}
-
 func CreateSubscriber(conf config.SubscriberConfig, logger watermill.LoggerAdapter) (message.Subscriber, error) {
+	if conf.GoChannel.Enabled {
+		return CreateGoChannel(conf.GoChannel, logger), nil
+	}
+
 	if conf.Ensign.Enabled {
 		return CreateEnsignSubscriber(conf.Ensign, logger)
 	}
@@ -58,6 +75,17 @@ func CreateSubscriber(conf config.SubscriberConfig, logger watermill.LoggerAdapt
 	return nil, errors.New("invalid configuration: no subscriber enabled")
 }
 
+// SubscriberGroup returns a subscriber that receives every message on the topics it
+// subscribes to rather than sharing them with the other users of the subscriber. Kafka
+// subscribers are moved to a consumer group of their own named after the group; other
+// subscribers are returned unmodified. A new subscriber must be closed separately.
+func SubscriberGroup(subscriber message.Subscriber, group string) message.Subscriber {
+	if kafka, ok := subscriber.(*KafkaSubscriber); ok {
+		return kafka.Group(group)
+	}
+	return subscriber
+}
+
 func CreateEnsignSubscriber(conf config.EnsignConfig, logger watermill.LoggerAdapter) (message.Subscriber, error) {
 	// TODO: move the ensign config to the watermill-ensign library to avoid multi-import
 	opts := ensign.SubscriberConfig{
@@ -71,6 +99,31 @@ func CreateEnsignSubscriber(conf config.EnsignConfig, logger watermill.LoggerAda
 	return ensign.NewSubscriber(opts, logger)
 }
 
-func CreateKafkaSubscriber(conf config.KafkaConfig, logger watermill.LoggerAdapter) (message.Subscriber, error) {
-	return nil, errors.New("not implemented yet")
+// CreatePubSub creates the publisher and subscriber of a Baleen service. When both are
+// configured to use the in-process pub/sub they share a single gochannel so that the
+// messages published by the service are delivered to its subscribers.
+func CreatePubSub(pubconf config.PublisherConfig, subconf config.SubscriberConfig, logger watermill.LoggerAdapter) (publisher message.Publisher, subscriber message.Subscriber, err error) {
+	if pubconf.GoChannel.Enabled && subconf.GoChannel.Enabled {
+		pubsub := CreateGoChannel(pubconf.GoChannel, logger)
+		return pubsub, pubsub, nil
+	}
+
+	if publisher, err = CreatePublisher(pubconf, logger); err != nil {
+		return nil, nil, err
+	}
+
+	if subscriber, err = CreateSubscriber(subconf, logger); err != nil {
+		publisher.Close()
+		return nil, nil, err
+	}
+	return publisher, subscriber, nil
+}
+
+// CreateGoChannel returns a new in-process pub/sub. Messages are only delivered to
+// subscribers of the same instance and the instance cannot be reused once closed.
+func CreateGoChannel(conf config.GoChannelConfig, logger watermill.LoggerAdapter) *gochannel.GoChannel {
+	return gochannel.NewGoChannel(gochannel.Config{
+		OutputChannelBuffer: conf.BufferSize,
+		Persistent:          conf.Persistent,
+	}, logger)
 }
@@ -0,0 +1,85 @@
+package baleen_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/rotationalio/baleen"
+	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoChannelPubSub(t *testing.T) {
+	// The ensign configs are enabled by default, the gochannel should take precedence.
+	pubconf := config.PublisherConfig{
+		Ensign:    config.EnsignConfig{Enabled: true},
+		GoChannel: config.GoChannelConfig{Enabled: true, BufferSize: 8},
+	}
+	subconf := config.SubscriberConfig{
+		Ensign:    config.EnsignConfig{Enabled: true},
+		GoChannel: config.GoChannelConfig{Enabled: true, BufferSize: 8},
+	}
+	require.NoError(t, pubconf.Validate())
+	require.NoError(t, subconf.Validate())
+
+	publisher, subscriber, err := baleen.CreatePubSub(pubconf, subconf, watermill.NopLogger{})
+	require.NoError(t, err, "could not create gochannel pub/sub")
+	require.Same(t, publisher, subscriber, "expected the publisher and subscriber to share the in-process pub/sub")
+	defer publisher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	C, err := subscriber.Subscribe(ctx, baleen.TopicSubscriptions)
+	require.NoError(t, err, "could not subscribe to the in-process pub/sub")
+
+	sub := &events.Subscription{FeedID: "example", FeedURL: "https://example.com/rss"}
+	msg, err := events.Marshal(sub, watermill.NewULID())
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(baleen.TopicSubscriptions, msg))
+
+	select {
+	case rep := <-C:
+		cmp, err := events.UnmarshalSubscription(rep)
+		require.NoError(t, err)
+		require.Equal(t, sub, cmp)
+		rep.Ack()
+	case <-ctx.Done():
+		t.Fatal("did not receive message from the in-process pub/sub")
+	}
+}
+
+func TestGoChannelPubSubClosed(t *testing.T) {
+	pubconf := config.PublisherConfig{GoChannel: config.GoChannelConfig{Enabled: true, BufferSize: 8}}
+	subconf := config.SubscriberConfig{GoChannel: config.GoChannelConfig{Enabled: true, BufferSize: 8}}
+
+	// Closing the pub/sub of one service must not affect the pub/sub of the next.
+	publisher, _, err := baleen.CreatePubSub(pubconf, subconf, watermill.NopLogger{})
+	require.NoError(t, err, "could not create gochannel pub/sub")
+	require.NoError(t, publisher.Close())
+
+	publisher, subscriber, err := baleen.CreatePubSub(pubconf, subconf, watermill.NopLogger{})
+	require.NoError(t, err, "could not create gochannel pub/sub")
+	defer publisher.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	C, err := subscriber.Subscribe(ctx, baleen.TopicSubscriptions)
+	require.NoError(t, err, "could not subscribe after a previous pub/sub was closed")
+
+	msg, err := events.Marshal(&events.Subscription{FeedID: "example"}, watermill.NewULID())
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(baleen.TopicSubscriptions, msg), "could not publish after a previous pub/sub was closed")
+
+	select {
+	case rep := <-C:
+		require.Equal(t, msg.UUID, rep.UUID)
+		rep.Ack()
+	case <-ctx.Done():
+		t.Fatal("did not receive message from the in-process pub/sub")
+	}
+}
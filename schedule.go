@@ -0,0 +1,100 @@
+package baleen
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/fetch"
+)
+
+// The number of recent items used to estimate the posting frequency of a feed.
+const scheduleItems = 10
+
+// Schedule computes the polling interval for each feed from the hints provided by the
+// publisher and the observed posting frequency so that busy feeds are polled often and
+// dormant feeds are polled rarely. The interval is always bounded by Min and Max.
+type Schedule struct {
+	Default  time.Duration // the interval used when the feed provides no hints
+	Min      time.Duration // the shortest interval between polls of a feed
+	Max      time.Duration // the longest interval between polls of a feed
+	Inactive time.Duration // the interval between polls of a deactivated feed
+}
+
+// NewSchedule creates a schedule from the feed sync configuration.
+func NewSchedule(conf config.FeedSyncConfig) Schedule {
+	return Schedule{
+		Default:  conf.Interval,
+		Min:      conf.MinInterval,
+		Max:      conf.MaxInterval,
+		Inactive: conf.InactiveInterval,
+	}
+}
+
+// Interval returns the duration until the feed should next be polled. The observed
+// posting frequency is used as a baseline; the feed's declared TTL and the response's
+// cache expiration are treated as requests not to poll any sooner than specified.
+func (s Schedule) Interval(feed *gofeed.Feed, expires, now time.Time) time.Duration {
+	interval := s.Default
+	if observed := postingFrequency(feed, now); observed > 0 {
+		interval = observed
+	}
+
+	if ttl := fetch.TTL(feed); ttl > interval {
+		interval = ttl
+	}
+
+	if cache := expires.Sub(now); cache > interval {
+		interval = cache
+	}
+
+	return s.Bound(interval)
+}
+
+// Bound the interval by the minimum and maximum intervals of the schedule.
+func (s Schedule) Bound(interval time.Duration) time.Duration {
+	if s.Min > 0 && interval < s.Min {
+		return s.Min
+	}
+
+	if s.Max > 0 && interval > s.Max {
+		return s.Max
+	}
+	return interval
+}
+
+// Estimates the average time between posts from the most recent dated items in the
+// feed, including the time since the last post so that dormant feeds slow down.
+// Returns zero if there are not enough dated items to make an estimate.
+func postingFrequency(feed *gofeed.Feed, now time.Time) time.Duration {
+	if feed == nil {
+		return 0
+	}
+
+	dates := make([]time.Time, 0, len(feed.Items))
+	for _, item := range feed.Items {
+		switch {
+		case item.PublishedParsed != nil:
+			dates = append(dates, *item.PublishedParsed)
+		case item.UpdatedParsed != nil:
+			dates = append(dates, *item.UpdatedParsed)
+		}
+	}
+
+	if len(dates) < 2 {
+		return 0
+	}
+
+	// Use only the most recent items to estimate the current posting frequency
+	sort.Slice(dates, func(i, j int) bool { return dates[i].After(dates[j]) })
+	if len(dates) > scheduleItems {
+		dates = dates[:scheduleItems]
+	}
+
+	oldest := dates[len(dates)-1]
+	if !oldest.Before(now) {
+		return 0
+	}
+	return now.Sub(oldest) / time.Duration(len(dates))
+}
@@ -0,0 +1,93 @@
+package baleen_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/rotationalio/baleen"
+	"github.com/rotationalio/baleen/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	now := time.Now()
+	schedule := baleen.NewSchedule(config.FeedSyncConfig{
+		Interval:    time.Hour,
+		MinInterval: 15 * time.Minute,
+		MaxInterval: 24 * time.Hour,
+	})
+
+	testCases := []struct {
+		name     string
+		feed     *gofeed.Feed
+		expires  time.Time
+		expected time.Duration
+	}{
+		{
+			"no hints",
+			&gofeed.Feed{},
+			time.Time{},
+			time.Hour,
+		},
+		{
+			"busy feed",
+			postedEvery(now, 5*time.Minute, 20),
+			time.Time{},
+			15 * time.Minute,
+		},
+		{
+			"regular feed",
+			postedEvery(now, 2*time.Hour, 10),
+			time.Time{},
+			2 * time.Hour,
+		},
+		{
+			"dormant feed",
+			postedEvery(now.Add(-90*24*time.Hour), 24*time.Hour, 5),
+			time.Time{},
+			24 * time.Hour,
+		},
+		{
+			"ttl longer than posting frequency",
+			withTTL(postedEvery(now, 30*time.Minute, 10), "180"),
+			time.Time{},
+			3 * time.Hour,
+		},
+		{
+			"cache expiration longer than posting frequency",
+			postedEvery(now, 30*time.Minute, 10),
+			now.Add(90 * time.Minute),
+			90 * time.Minute,
+		},
+		{
+			"cache expiration shorter than posting frequency",
+			postedEvery(now, 4*time.Hour, 10),
+			now.Add(time.Minute),
+			4 * time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			interval := schedule.Interval(tc.feed, tc.expires, now)
+			require.InDelta(t, tc.expected, interval, float64(time.Second))
+		})
+	}
+}
+
+// Creates a feed with n items posted at the specified interval, the most recent of which
+// was posted interval before latest.
+func postedEvery(latest time.Time, interval time.Duration, n int) *gofeed.Feed {
+	feed := &gofeed.Feed{Items: make([]*gofeed.Item, 0, n)}
+	for i := 1; i <= n; i++ {
+		published := latest.Add(-time.Duration(i) * interval)
+		feed.Items = append(feed.Items, &gofeed.Item{PublishedParsed: &published})
+	}
+	return feed
+}
+
+func withTTL(feed *gofeed.Feed, ttl string) *gofeed.Feed {
+	feed.Custom = map[string]string{"ttl": ttl}
+	return feed
+}
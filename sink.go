@@ -0,0 +1,168 @@
+package baleen
+
+import (
+	"errors"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/events"
+	"github.com/rotationalio/baleen/store"
+	mime "github.com/rotationalio/go-ensign/mimetype/v1beta1"
+	"github.com/rs/zerolog/log"
+)
+
+func (s *Baleen) AddS3Sink(conf config.AWSConfig) (err error) {
+	var sink *S3Sink
+	if sink, err = NewS3Sink(conf); err != nil {
+		return err
+	}
+
+	// Add the handler to consume messages from the documents topic.
+	handler := s.router.AddNoPublisherHandler(
+		HandlerS3Sink,
+		TopicDocuments,
+		SubscriberGroup(s.subscriber, HandlerS3Sink),
+		sink.Handle,
+	)
+
+	// Filter the type of messages handled
+	handler.AddMiddleware(
+		TypeFilter(mime.ApplicationMsgPack.MimeType(), events.TypeDocument),
+	)
+
+	return nil
+}
+
+// S3Sink uploads the documents fetched by Baleen to an S3 bucket so that they can be
+// used as a corpus. The session is reused for all uploads.
+type S3Sink struct {
+	session *session.Session
+	bucket  string
+}
+
+func NewS3Sink(conf config.AWSConfig) (_ *S3Sink, err error) {
+	if !conf.Enabled {
+		return nil, errors.New("s3 sink is not enabled")
+	}
+
+	if err = conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	creds := &store.AWSCredentials{
+		Region:    conf.Region,
+		Bucket:    conf.Bucket,
+		Endpoint:  conf.Endpoint,
+		PathStyle: conf.PathStyle,
+	}
+
+	sink := &S3Sink{bucket: conf.Bucket}
+	if sink.session, err = store.GetSession(creds); err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *S3Sink) Handle(msg *message.Message) (err error) {
+	var doc *events.Document
+	if doc, err = events.UnmarshalDocument(msg); err != nil {
+		return err
+	}
+
+	// Documents that could not be fetched have no content to store
+	if !doc.Active || len(doc.Content) == 0 {
+		return nil
+	}
+
+	if err = store.Upload(s.session, StoreDocument(doc), s.bucket); err != nil {
+		log.Warn().Err(err).Str("feed_id", doc.FeedID).Str("url", doc.Link).Msg("could not upload document to s3")
+		return err
+	}
+	return nil
+}
+
+// StoreDocument converts a document event into its storage representation.
+func StoreDocument(doc *events.Document) store.Document {
+	return store.Document{
+		FeedID:       doc.FeedID,
+		LanguageCode: doc.Language,
+		Year:         doc.Year,
+		Month:        doc.Month,
+		Day:          doc.Day,
+		Title:        doc.Title,
+		Description:  doc.Description,
+		Content:      doc.Content,
+		Encoding:     doc.Encoding,
+		Charset:      doc.Charset,
+		Link:         doc.Link,
+		Source:       doc.Source,
+
+		DetectedLanguage:   doc.DetectedLanguage,
+		LanguageConfidence: doc.LanguageConfidence,
+
+		Canonical: doc.Canonical,
+		SiteName:  doc.SiteName,
+		Author:    doc.Author,
+		Keywords:  doc.Keywords,
+	}
+}
+
+func (s *Baleen) AddFileSink(conf config.FileSinkConfig) (err error) {
+	var sink *FileSink
+	if sink, err = NewFileSink(conf); err != nil {
+		return err
+	}
+
+	// Add the handler to consume messages from the documents topic.
+	handler := s.router.AddNoPublisherHandler(
+		HandlerFileSink,
+		TopicDocuments,
+		SubscriberGroup(s.subscriber, HandlerFileSink),
+		sink.Handle,
+	)
+
+	// Filter the type of messages handled
+	handler.AddMiddleware(
+		TypeFilter(mime.ApplicationMsgPack.MimeType(), events.TypeDocument),
+	)
+
+	return nil
+}
+
+// FileSink writes the documents fetched by Baleen to a directory on the local
+// filesystem using the same layout as the S3 sink.
+type FileSink struct {
+	path    string
+	sidecar bool
+}
+
+func NewFileSink(conf config.FileSinkConfig) (_ *FileSink, err error) {
+	if !conf.Enabled {
+		return nil, errors.New("file sink is not enabled")
+	}
+
+	if err = conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &FileSink{path: conf.Path, sidecar: conf.Sidecar}, nil
+}
+
+func (s *FileSink) Handle(msg *message.Message) (err error) {
+	var doc *events.Document
+	if doc, err = events.UnmarshalDocument(msg); err != nil {
+		return err
+	}
+
+	// Documents that could not be fetched have no content to store
+	if !doc.Active || len(doc.Content) == 0 {
+		return nil
+	}
+
+	if err = store.Write(s.path, StoreDocument(doc), s.sidecar); err != nil {
+		log.Warn().Err(err).Str("feed_id", doc.FeedID).Str("url", doc.Link).Msg("could not write document to file sink")
+		return err
+	}
+	return nil
+}
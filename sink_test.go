@@ -0,0 +1,142 @@
+package baleen_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/rotationalio/baleen"
+	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/events"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3Sink(t *testing.T) {
+	bucket := useS3Server(t)
+	sink, err := baleen.NewS3Sink(config.AWSConfig{
+		Enabled:   true,
+		Region:    "us-east-1",
+		Bucket:    "corpus",
+		Endpoint:  bucket.URL,
+		PathStyle: true,
+	})
+	require.NoError(t, err, "could not create s3 sink")
+
+	doc := &events.Document{
+		Active:   true,
+		FeedID:   "feed1",
+		Language: "en",
+		Year:     2023,
+		Month:    "June",
+		Day:      5,
+		Title:    "Hello World Post",
+		Content:  []byte("<html><body><p>Hello World</p></body></html>"),
+		Link:     "http://example.org/item/1",
+	}
+
+	msg, err := events.Marshal(doc, watermill.NewULID())
+	require.NoError(t, err)
+	require.NoError(t, sink.Handle(msg), "could not upload document")
+
+	objects := bucket.Objects()
+	require.Len(t, objects, 1)
+	for key, content := range objects {
+		require.True(t, strings.HasPrefix(key, "/corpus/en/2023/June/5/feed1/"), "unexpected object key %q", key)
+		require.True(t, strings.HasSuffix(key, ".html"), "unexpected object key %q", key)
+		require.Equal(t, doc.Content, content)
+	}
+
+	// Documents that could not be fetched should not be uploaded
+	doc = &events.Document{Active: false, StatusCode: http.StatusNotFound, FeedID: "feed1", Link: "http://example.org/item/2"}
+	msg, err = events.Marshal(doc, watermill.NewULID())
+	require.NoError(t, err)
+	require.NoError(t, sink.Handle(msg))
+	require.Len(t, bucket.Objects(), 1)
+
+	// The sink must be enabled and configured
+	_, err = baleen.NewS3Sink(config.AWSConfig{Enabled: false})
+	require.Error(t, err)
+
+	_, err = baleen.NewS3Sink(config.AWSConfig{Enabled: true, Region: "us-east-1"})
+	require.Error(t, err)
+}
+
+// s3Server is a minimal S3-compatible stand-in that stores the objects put to it.
+type s3Server struct {
+	*httptest.Server
+	sync.Mutex
+	objects map[string][]byte
+}
+
+func useS3Server(t *testing.T) *s3Server {
+	t.Setenv("AWS_ACCESS_KEY_ID", "testing")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "supersecret")
+
+	srv := &s3Server{objects: make(map[string][]byte)}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		srv.Lock()
+		srv.objects[r.URL.Path] = body
+		srv.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *s3Server) Objects() map[string][]byte {
+	s.Lock()
+	defer s.Unlock()
+	return s.objects
+}
+
+func TestFileSink(t *testing.T) {
+	root := t.TempDir()
+	sink, err := baleen.NewFileSink(config.FileSinkConfig{Enabled: true, Path: root, Sidecar: true})
+	require.NoError(t, err, "could not create file sink")
+
+	doc := &events.Document{
+		Active:   true,
+		FeedID:   "feed1",
+		Language: "en",
+		Year:     2023,
+		Month:    "June",
+		Day:      5,
+		Title:    "Hello World Post",
+		Content:  []byte("<html><body><p>Hello World</p></body></html>"),
+		Link:     "http://example.org/item/1",
+	}
+
+	msg, err := events.Marshal(doc, watermill.NewULID())
+	require.NoError(t, err)
+	require.NoError(t, sink.Handle(msg), "could not write document")
+
+	path := filepath.Join(root, filepath.FromSlash(baleen.StoreDocument(doc).Path()))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err, "document was not written to the file sink")
+	require.Equal(t, doc.Content, content)
+	require.FileExists(t, strings.TrimSuffix(path, ".html")+".json")
+
+	// The sink must be enabled and configured
+	_, err = baleen.NewFileSink(config.FileSinkConfig{Enabled: false})
+	require.Error(t, err)
+
+	_, err = baleen.NewFileSink(config.FileSinkConfig{Enabled: true})
+	require.Error(t, err)
+}
@@ -0,0 +1,61 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Write a single Document to the local filesystem in a directory tree rooted at the
+// specified path, using the same layout as the documents uploaded to S3. If sidecar is
+// true, the metadata of the document is also written alongside the content as JSON.
+func Write(root string, doc Document, sidecar bool) (err error) {
+	var name string
+	if name, err = localPath(root, doc); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+
+	log.Debug().Str("path", name).Msg("storing document to local filesystem")
+	if err = os.WriteFile(name, doc.Content, 0644); err != nil {
+		return err
+	}
+
+	if sidecar {
+		var meta []byte
+		if meta, err = json.MarshalIndent(doc, "", "  "); err != nil {
+			return err
+		}
+
+		if err = os.WriteFile(strings.TrimSuffix(name, filepath.Ext(name))+".json", meta, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Returns the path of the document in the directory tree rooted at the specified path,
+// returning an error if the fields of the document used as path segments would escape
+// the root, e.g. a feed id or language code containing a separator or "..".
+func localPath(root string, doc Document) (_ string, err error) {
+	for _, segment := range []string{doc.LanguageCode, doc.Month, doc.FeedID} {
+		if segment == "." || segment == ".." || strings.ContainsAny(segment, `/\`) {
+			return "", fmt.Errorf("invalid document path segment %q", segment)
+		}
+	}
+
+	name := filepath.Join(root, filepath.FromSlash(doc.Path()))
+
+	var rel string
+	if rel, err = filepath.Rel(root, name); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("document path %q is outside of %s", doc.Path(), root)
+	}
+	return name, nil
+}
@@ -0,0 +1,81 @@
+package store_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rotationalio/baleen/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	root := t.TempDir()
+	doc := store.Document{
+		FeedID:       "feed1",
+		LanguageCode: "en",
+		Year:         2023,
+		Month:        "June",
+		Day:          5,
+		Title:        "Hello World Post",
+		Content:      []byte("<html><body><p>Hello World</p></body></html>"),
+		Link:         "http://example.org/item/1",
+	}
+
+	path := doc.Path()
+	require.Equal(t, "en/2023/June/5/feed1/"+doc.Hash()+".html", path)
+
+	// Without a sidecar only the content should be written
+	require.NoError(t, store.Write(root, doc, false))
+	content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(path)))
+	require.NoError(t, err, "document was not written to the expected path")
+	require.Equal(t, doc.Content, content)
+
+	sidecar := filepath.Join(root, "en", "2023", "June", "5", "feed1", doc.Hash()+".json")
+	require.NoFileExists(t, sidecar)
+
+	// With a sidecar the metadata should be written next to the content
+	require.NoError(t, store.Write(root, doc, true))
+	require.FileExists(t, sidecar)
+
+	data, err := os.ReadFile(sidecar)
+	require.NoError(t, err)
+
+	meta := store.Document{}
+	require.NoError(t, json.Unmarshal(data, &meta))
+	require.Equal(t, doc.Title, meta.Title)
+	require.Equal(t, doc.Link, meta.Link)
+	require.Empty(t, meta.Content, "content should not be written to the sidecar")
+}
+
+func TestWriteOutsideRoot(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "corpus")
+	valid := store.Document{
+		FeedID:       "feed1",
+		LanguageCode: "en",
+		Year:         2023,
+		Month:        "June",
+		Day:          5,
+		Content:      []byte("<html><body><p>Hello World</p></body></html>"),
+	}
+
+	testCases := []func(doc *store.Document){
+		func(doc *store.Document) { doc.LanguageCode = "../../../../tmp/x" },
+		func(doc *store.Document) { doc.LanguageCode = ".." },
+		func(doc *store.Document) { doc.Month = "../.." },
+		func(doc *store.Document) { doc.FeedID = ".." },
+		func(doc *store.Document) { doc.FeedID = `..\..\feed1` },
+	}
+
+	for i, tc := range testCases {
+		doc := valid
+		tc(&doc)
+		require.Error(t, store.Write(root, doc, true), "expected error for test case %d", i)
+	}
+
+	// Nothing should have been written outside of the corpus root
+	entries, err := os.ReadDir(filepath.Dir(root))
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
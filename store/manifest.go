@@ -1,9 +1,24 @@
 package store
 
 import (
+	"encoding/json"
+	"errors"
+	"time"
+
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Key prefixes used to namespace the records stored in the manifest database.
+var (
+	feedsPrefix = []byte("feeds::")
+	itemsPrefix = []byte("items::")
+	keySep      = []byte("::")
 )
 
+var ErrNotFound = errors.New("record not found in manifest")
+
 // MustOpen either initializes a new leveldb database at the path of the string provided
 // or opens an existing leveldb database found at that path.
 func MustOpen(path string) *leveldb.DB {
@@ -14,3 +29,187 @@ func MustOpen(path string) *leveldb.DB {
 
 	return db
 }
+
+// Manifest persists the feed subscriptions handled by Baleen to a leveldb database so
+// that subscriptions and their fetch state survive restarts of the service.
+type Manifest struct {
+	db *leveldb.DB
+}
+
+// OpenManifest opens the leveldb manifest at the specified path using MustOpen.
+func OpenManifest(path string) *Manifest {
+	return &Manifest{db: MustOpen(path)}
+}
+
+// Open an existing leveldb manifest at the specified path, returning an error rather
+// than panicking if the manifest does not exist or is locked by another process.
+func Open(path string) (_ *Manifest, err error) {
+	var db *leveldb.DB
+	if db, err = leveldb.OpenFile(path, &opt.Options{ErrorIfMissing: true}); err != nil {
+		return nil, err
+	}
+	return &Manifest{db: db}, nil
+}
+
+// Get a feed from the manifest by its feed url.
+func (m *Manifest) Get(url string) (feed *Feed, err error) {
+	var val []byte
+	if val, err = m.db.Get(feedKey(url), nil); err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	feed = &Feed{}
+	if err = json.Unmarshal(val, feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// Put a feed into the manifest, overwriting any previous record with the same url.
+func (m *Manifest) Put(feed *Feed) (err error) {
+	if feed.URL == "" {
+		return errors.New("cannot store a feed without a url")
+	}
+
+	var val []byte
+	if val, err = json.Marshal(feed); err != nil {
+		return err
+	}
+	return m.db.Put(feedKey(feed.URL), val, nil)
+}
+
+// Delete a feed from the manifest by its feed url.
+func (m *Manifest) Delete(url string) error {
+	return m.db.Delete(feedKey(url), nil)
+}
+
+// Feeds returns all of the feeds currently stored in the manifest.
+func (m *Manifest) Feeds() (feeds []*Feed, err error) {
+	iter := m.db.NewIterator(util.BytesPrefix(feedsPrefix), nil)
+	defer iter.Release()
+
+	feeds = make([]*Feed, 0)
+	for iter.Next() {
+		feed := &Feed{}
+		if err = json.Unmarshal(iter.Value(), feed); err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err = iter.Error(); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// An Item is an entry in the seen-item index of a feed, used to determine if a feed item
+// has already been published. The hash is used to detect if the item has changed.
+type Item struct {
+	Key       string    `json:"key"`
+	Hash      string    `json:"hash"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// GetItem returns the seen item with the specified key for the feed.
+func (m *Manifest) GetItem(feedID, key string) (item *Item, err error) {
+	var val []byte
+	if val, err = m.db.Get(itemKey(feedID, key), nil); err != nil {
+		if errors.Is(err, leveldb.ErrNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	item = &Item{}
+	if err = json.Unmarshal(val, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// PutItem adds or updates the seen item in the index for the feed.
+func (m *Manifest) PutItem(feedID string, item *Item) (err error) {
+	if feedID == "" || item.Key == "" {
+		return errors.New("cannot store an item without a feed id and key")
+	}
+
+	var val []byte
+	if val, err = json.Marshal(item); err != nil {
+		return err
+	}
+	return m.db.Put(itemKey(feedID, item.Key), val, nil)
+}
+
+// PruneItems deletes all seen items for the feed that were last seen before the
+// specified timestamp, returning the number of items that were deleted.
+func (m *Manifest) PruneItems(feedID string, before time.Time) (n int, err error) {
+	iter := m.db.NewIterator(util.BytesPrefix(itemKey(feedID, "")), nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		item := &Item{}
+		if err = json.Unmarshal(iter.Value(), item); err != nil {
+			return 0, err
+		}
+
+		if item.LastSeen.Before(before) {
+			batch.Delete(append([]byte(nil), iter.Key()...))
+		}
+	}
+
+	if err = iter.Error(); err != nil {
+		return 0, err
+	}
+
+	if batch.Len() > 0 {
+		if err = m.db.Write(batch, nil); err != nil {
+			return 0, err
+		}
+	}
+	return batch.Len(), nil
+}
+
+// DeleteItems deletes all of the seen items for the feed, e.g. when the feed is removed.
+func (m *Manifest) DeleteItems(feedID string) error {
+	iter := m.db.NewIterator(util.BytesPrefix(itemKey(feedID, "")), nil)
+	defer iter.Release()
+
+	batch := new(leveldb.Batch)
+	for iter.Next() {
+		batch.Delete(append([]byte(nil), iter.Key()...))
+	}
+
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+	return m.db.Write(batch, nil)
+}
+
+// Close the underlying leveldb database.
+func (m *Manifest) Close() error {
+	return m.db.Close()
+}
+
+func feedKey(url string) []byte {
+	key := make([]byte, 0, len(feedsPrefix)+len(url))
+	key = append(key, feedsPrefix...)
+	return append(key, []byte(url)...)
+}
+
+func itemKey(feedID, key string) []byte {
+	out := make([]byte, 0, len(itemsPrefix)+len(feedID)+len(keySep)+len(key))
+	out = append(out, itemsPrefix...)
+	out = append(out, []byte(feedID)...)
+	out = append(out, keySep...)
+	return append(out, []byte(key)...)
+}
@@ -0,0 +1,124 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rotationalio/baleen/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest")
+	db := store.OpenManifest(path)
+
+	feeds, err := db.Feeds()
+	require.NoError(t, err, "could not list feeds in empty manifest")
+	require.Len(t, feeds, 0)
+
+	_, err = db.Get("https://example.com/rss")
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	feed := &store.Feed{
+		URL:          "https://example.com/rss",
+		Active:       true,
+		FeedID:       "example",
+		Title:        "Example Feed",
+		FeedType:     "rss",
+		SiteURL:      "https://example.com",
+		ETag:         "ABCDEFG",
+		LastModified: "Wed, 21 Oct 2015 07:28:00 GMT",
+		SyncedAt:     time.Now().Truncate(time.Second).UTC(),
+	}
+	require.NoError(t, db.Put(feed), "could not put feed")
+	require.Error(t, db.Put(&store.Feed{FeedID: "nourl"}), "should not be able to put a feed without a url")
+
+	// Ensure the feed is persisted when the database is reopened
+	require.NoError(t, db.Close())
+	db = store.OpenManifest(path)
+	defer db.Close()
+
+	cmp, err := db.Get(feed.URL)
+	require.NoError(t, err, "could not get feed")
+	require.Equal(t, feed, cmp)
+
+	feeds, err = db.Feeds()
+	require.NoError(t, err, "could not list feeds")
+	require.Len(t, feeds, 1)
+
+	require.NoError(t, db.Delete(feed.URL), "could not delete feed")
+	_, err = db.Get(feed.URL)
+	require.ErrorIs(t, err, store.ErrNotFound)
+}
+
+func TestSeenItems(t *testing.T) {
+	db := store.OpenManifest(filepath.Join(t.TempDir(), "manifest"))
+	defer db.Close()
+
+	now := time.Now().Truncate(time.Second).UTC()
+	items := []*store.Item{
+		{Key: "a", Hash: "1", FirstSeen: now.Add(-72 * time.Hour), LastSeen: now.Add(-48 * time.Hour)},
+		{Key: "b", Hash: "2", FirstSeen: now.Add(-72 * time.Hour), LastSeen: now},
+		{Key: "c", Hash: "3", FirstSeen: now, LastSeen: now},
+	}
+
+	for _, item := range items {
+		require.NoError(t, db.PutItem("feed1", item), "could not put item")
+	}
+	require.NoError(t, db.PutItem("feed2", items[0]), "could not put item")
+	require.Error(t, db.PutItem("", items[0]), "should not be able to put an item without a feed id")
+
+	cmp, err := db.GetItem("feed1", "b")
+	require.NoError(t, err)
+	require.Equal(t, items[1], cmp)
+
+	_, err = db.GetItem("feed1", "d")
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	// Only stale items for the specified feed should be pruned
+	n, err := db.PruneItems("feed1", now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, err = db.GetItem("feed1", "a")
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = db.GetItem("feed2", "a")
+	require.NoError(t, err, "item in another feed should not have been pruned")
+
+	// Items should not be listed as feeds
+	feeds, err := db.Feeds()
+	require.NoError(t, err)
+	require.Len(t, feeds, 0)
+
+	// Deleting the items of a feed should not delete the items of other feeds
+	require.NoError(t, db.DeleteItems("feed1"))
+	_, err = db.GetItem("feed1", "b")
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	_, err = db.GetItem("feed2", "a")
+	require.NoError(t, err, "item in another feed should not have been deleted")
+}
+
+func TestOpenManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest")
+	_, err := store.Open(path)
+	require.Error(t, err, "should not create a manifest that does not exist")
+
+	db := store.OpenManifest(path)
+	require.NoError(t, db.Put(&store.Feed{URL: "https://example.com/rss", Categories: []string{"News"}}))
+
+	// The manifest is locked while it is open
+	_, err = store.Open(path)
+	require.Error(t, err, "should not open a manifest that is locked")
+	require.NoError(t, db.Close())
+
+	db, err = store.Open(path)
+	require.NoError(t, err, "could not open existing manifest")
+	defer db.Close()
+
+	feed, err := db.Get("https://example.com/rss")
+	require.NoError(t, err)
+	require.Equal(t, []string{"News"}, feed.Categories)
+}
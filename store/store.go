@@ -6,20 +6,25 @@ package store
 import (
 	"bytes"
 	"errors"
-	"fmt"
 	"net/http"
+	"path"
 	"strconv"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/rs/zerolog/log"
 	"github.com/spaolacci/murmur3"
 )
 
-// AWSCredentials stores the region and bucket needed to access an S3 bucket.
+// AWSCredentials stores the region and bucket needed to access an S3 bucket. The
+// endpoint and path style are optional and allow S3-compatible stores to be used.
 type AWSCredentials struct {
-	Region string
-	Bucket string
+	Region    string
+	Bucket    string
+	Endpoint  string
+	PathStyle bool
 }
 
 // A Document is the generic representation of an individual entry in a feed
@@ -31,15 +36,58 @@ type Document struct {
 	Day          int
 	Title        string
 	Description  string
-	Content      []byte
+	Content      []byte `json:"-"`
 	Encoding     string `json:",omitempty"`
+	Charset      string `json:",omitempty"`
 	Link         string
+	Source       string `json:",omitempty"`
+
+	// The language identified from the text of the document and its confidence.
+	DetectedLanguage   string  `json:",omitempty"`
+	LanguageConfidence float64 `json:",omitempty"`
+
+	// Metadata extracted from the head of the document.
+	Canonical string   `json:",omitempty"`
+	SiteName  string   `json:",omitempty"`
+	Author    string   `json:",omitempty"`
+	Keywords  []string `json:",omitempty"`
 }
 
+// A Feed is the persisted representation of a subscription in the feed manifest,
+// including the conditional http state of the last fetch so that the feed can be
+// resumed without refetching unchanged content after a restart, and the health of the
+// feed (consecutive failures, last success, and the reason it was deactivated).
 type Feed struct {
-	URL    string `json:"url"`
-	Active bool   `json:"active"`
-	Error  string `json:"error"`
+	URL          string        `json:"url"`
+	Active       bool          `json:"active"`
+	Error        string        `json:"error"`
+	FeedID       string        `json:"feed_id"`
+	Title        string        `json:"title,omitempty"`
+	FeedType     string        `json:"feed_type,omitempty"`
+	SiteURL      string        `json:"site_url,omitempty"`
+	Categories   []string      `json:"categories,omitempty"`
+	ETag         string        `json:"etag,omitempty"`
+	LastModified string        `json:"last_modified,omitempty"`
+	SyncedAt     time.Time     `json:"synced_at,omitempty"`
+	NextSync     time.Time     `json:"next_sync,omitempty"`
+	Interval     time.Duration `json:"interval,omitempty"`
+	Failures     int           `json:"failures,omitempty"`
+	LastSuccess  time.Time     `json:"last_success,omitempty"`
+	Reason       string        `json:"reason,omitempty"`
+}
+
+// Path returns the slash separated path that the document is stored at, relative to the
+// root of the corpus, made up of the language code, year, month, day, feed ID, and the
+// hash of the content so that each document has a unique filename.
+func (d Document) Path() string {
+	return path.Join(d.LanguageCode, strconv.Itoa(d.Year), d.Month, strconv.Itoa(d.Day), d.FeedID, d.Hash()+".html")
+}
+
+// Hash the contents of the document to create a unique filename.
+func (d Document) Hash() string {
+	hasher := murmur3.New64()
+	hasher.Write(d.Content)
+	return strconv.FormatInt(int64(hasher.Sum64()), 10)
 }
 
 // VerifyCredentials is a helper function that verifies the credentials are correct and
@@ -53,7 +101,16 @@ func VerifyCredentials(creds *AWSCredentials) bool {
 // to an AWS connection and a nil, else a nil and the connection error.
 func GetSession(creds *AWSCredentials) (sesh *session.Session, err error) {
 	if VerifyCredentials(creds) {
-		sesh, err = session.NewSession(&aws.Config{Region: &creds.Region})
+		conf := &aws.Config{Region: &creds.Region}
+		if creds.Endpoint != "" {
+			conf.Endpoint = aws.String(creds.Endpoint)
+		}
+
+		if creds.PathStyle {
+			conf.S3ForcePathStyle = aws.Bool(true)
+		}
+
+		sesh, err = session.NewSession(conf)
 		if err != nil {
 			return nil, err
 		}
@@ -66,13 +123,9 @@ func GetSession(creds *AWSCredentials) (sesh *session.Session, err error) {
 // setting file information including name (the feedID, language code, year, month, day, and
 // hash of the content), the content size and type, and the encryption on the uploaded file.
 func Upload(s *session.Session, doc Document, bucket string) error {
-	// Hash the contents of the file and use the hash to create a unique filename
-	hasher := murmur3.New64()
-	hasher.Write([]byte(doc.Content))
-	hash := strconv.FormatInt(int64(hasher.Sum64()), 10)
-	name := doc.LanguageCode + "/" + strconv.Itoa(doc.Year) + "/" + doc.Month + "/" + strconv.Itoa(doc.Day) + "/" + doc.FeedID + "/" + hash + ".html"
+	name := doc.Path()
 
-	fmt.Printf("\n Storing %s to s3", name)
+	log.Debug().Str("bucket", bucket).Str("key", name).Msg("storing document to s3")
 
 	// Put the object to the S3 bucket
 	// TODO: compress doc.Content with gzip to save on storage costs
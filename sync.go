@@ -4,6 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ThreeDotsLabs/watermill"
@@ -12,8 +16,20 @@ import (
 	"github.com/rotationalio/baleen/config"
 	"github.com/rotationalio/baleen/events"
 	"github.com/rotationalio/baleen/fetch"
+	"github.com/rotationalio/baleen/metrics"
+	"github.com/rotationalio/baleen/store"
 	mime "github.com/rotationalio/go-ensign/mimetype/v1beta1"
 	"github.com/rs/zerolog/log"
+	"github.com/spaolacci/murmur3"
+)
+
+// The status code labels used in the metrics when a fetch fails without a response or
+// the response is not a feed, and when a document is created from feed content.
+const (
+	statusError      = "error"
+	statusDisallowed = "disallowed"
+	statusNotFeed    = "not_feed"
+	statusFeed       = "feed"
 )
 
 func (s *Baleen) AddFeedSync(conf config.FeedSyncConfig, publisher message.Publisher) (err error) {
@@ -24,9 +40,9 @@ func (s *Baleen) AddFeedSync(conf config.FeedSyncConfig, publisher message.Publi
 
 	// Add the handler to handle messages from the subscriptions topic.
 	handler := s.router.AddHandler(
-		"feed_sync",
+		HandlerFeedSync,
 		TopicSubscriptions,
-		s.subscriber,
+		SubscriberGroup(s.subscriber, HandlerFeedSync),
 		TopicFeeds,
 		s.publisher,
 		fsync.Handle,
@@ -39,6 +55,7 @@ func (s *Baleen) AddFeedSync(conf config.FeedSyncConfig, publisher message.Publi
 
 	// Add the plugin to start the fsync routine when the router is run.
 	s.router.AddPlugin(fsync.Start)
+	s.fsync = fsync
 	return nil
 }
 
@@ -47,9 +64,17 @@ func NewFeedSync(conf config.FeedSyncConfig, publisher message.Publisher) (*Feed
 		return nil, errors.New("feed sync is not enabled")
 	}
 
+	if conf.ManifestPath == "" {
+		return nil, errors.New("feed sync requires a manifest path")
+	}
+
+	manifest := NewManifest(store.OpenManifest(conf.ManifestPath))
+	manifest.Configure(conf)
+
 	return &FeedSync{
 		conf:      conf,
-		manifest:  make(Manifest),
+		manifest:  manifest,
+		hosts:     NewHostLimiter(conf.HostConcurrency),
 		stop:      make(chan struct{}),
 		publisher: publisher,
 	}, nil
@@ -58,11 +83,13 @@ func NewFeedSync(conf config.FeedSyncConfig, publisher message.Publisher) (*Feed
 type FeedSync struct {
 	conf      config.FeedSyncConfig
 	publisher message.Publisher
-	manifest  Manifest
+	manifest  *Manifest
+	hosts     *HostLimiter
 	stop      chan struct{}
+	running   sync.WaitGroup
 }
 
-func (f *FeedSync) Handle(msg *message.Message) (_ []*message.Message, err error) {
+func (f *FeedSync) Handle(msg *message.Message) (msgs []*message.Message, err error) {
 	// Parse the subscription
 	var info *events.Subscription
 	if info, err = events.UnmarshalSubscription(msg); err != nil {
@@ -75,10 +102,47 @@ func (f *FeedSync) Handle(msg *message.Message) (_ []*message.Message, err error
 	}
 
 	// Create or update the feed in the manifest
-	feed := f.manifest.Add(info)
+	var feed *Feed
+	moved := info.PreviousURL != ""
+	if feed, err = f.manifest.Add(info); err != nil {
+		return nil, err
+	}
+	metrics.Subscriptions.Set(float64(f.manifest.Len()))
+
+	// A feed that has moved was just synchronized by the node that detected the move so
+	// it is synchronized on its regular schedule rather than right now.
+	if moved {
+		return nil, nil
+	}
+
+	// Synchronize the feed right now; the items are recorded in the seen-item index once
+	// the router has published the messages and acked the subscription event.
+	var seen *seenItems
+	if msgs, seen, err = f.sync(feed); err != nil {
+		return nil, err
+	}
+	f.markSeenOnAck(msg, feed, seen)
+	return msgs, nil
+}
+
+// Records the items of the sync in the seen-item index once the message is acked, which
+// happens after the messages returned by the handler have been published. If the message
+// is nacked, the items are published again by the next sync of the feed.
+func (f *FeedSync) markSeenOnAck(msg *message.Message, feed *Feed, seen *seenItems) {
+	if seen == nil {
+		return
+	}
 
-	// Synchronize the feed right now
-	return feed.Sync()
+	f.running.Add(1)
+	go func() {
+		defer f.running.Done()
+		select {
+		case <-msg.Acked():
+			feed.markSeen(seen)
+		case <-msg.Nacked():
+		case <-f.stop:
+		}
+	}()
 }
 
 func (f *FeedSync) Start(r *message.Router) error {
@@ -86,118 +150,551 @@ func (f *FeedSync) Start(r *message.Router) error {
 		return errors.New("interval must be 1s or greater")
 	}
 
+	if f.conf.Tick < time.Second {
+		return errors.New("tick must be 1s or greater")
+	}
+
+	if f.conf.MaxInterval > 0 && f.conf.MinInterval > f.conf.MaxInterval {
+		return errors.New("min interval cannot be greater than max interval")
+	}
+
+	if f.conf.MaxFailures < 0 {
+		return errors.New("max failures cannot be negative")
+	}
+
+	// Reload the subscriptions persisted by previous runs of the feed sync.
+	if err := f.manifest.Load(); err != nil {
+		return err
+	}
+	log.Info().Int("nfeeds", f.manifest.Len()).Msg("feed manifest loaded")
+	metrics.Subscriptions.Set(float64(f.manifest.Len()))
+
+	f.running.Add(1)
 	go func() {
+		defer f.running.Done()
+
 		// Setup the feed sync background routine
-		ticker := time.NewTicker(f.conf.Interval)
+		ticker := time.NewTicker(f.conf.Tick)
+		defer ticker.Stop()
 
 		// Wait until the router starts running to start the feed sync process.
 		<-r.Running()
-		log.Info().Dur("interval", f.conf.Interval).Msg("feed_sync interval is running")
-		defer log.Info().Msg("feed_sync interval has stopped")
+		log.Info().Dur("tick", f.conf.Tick).Dur("interval", f.conf.Interval).Msg("feed_sync schedule is running")
+		defer log.Info().Msg("feed_sync schedule has stopped")
 
 		for {
 			// TODO: when the next version of watermill comes out, also select on handler.Stopped()
 			select {
 			case <-f.stop:
 				return
-			case <-ticker.C:
+			case now := <-ticker.C:
+				feeds := f.manifest.Due(now)
+				if len(feeds) == 0 {
+					continue
+				}
+
+				log.Info().Int("nfeeds", len(feeds)).Int("workers", f.conf.Workers).Msg("synchronizing feeds")
+				f.syncAll(feeds)
 			}
+		}
+	}()
+	return nil
+}
 
-			log.Info().Int("nfeeds", len(f.manifest)).Msg("synchronizing feeds")
+// Stop the feed sync background routine and close the manifest once any in-progress
+// synchronizations have completed.
+func (f *FeedSync) Stop() error {
+	close(f.stop)
+	f.running.Wait()
+	return f.manifest.Close()
+}
 
-			// Handle subscriptions
-			for _, feed := range f.manifest {
-				msgs, err := feed.Sync()
+// Synchronize all of the feeds using a bounded pool of workers and publish the results.
+// The feeds are interleaved by host so that workers are not all waiting on the same
+// host's concurrency limit while feeds from other hosts are pending.
+func (f *FeedSync) syncAll(feeds []*Feed) {
+	workers := f.conf.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var wg sync.WaitGroup
+	queue := make(chan *Feed)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for feed := range queue {
+				msgs, seen, err := f.sync(feed)
 				if err != nil {
-					log.Error().Err(err).Str("feed_id", feed.info.FeedID).Str("url", feed.info.FeedURL).Msg("could not synchronize feed")
+					info := feed.Info()
+					log.Error().Err(err).Str("feed_id", info.FeedID).Str("url", info.FeedURL).Msg("could not synchronize feed")
 					continue
 				}
 
 				if err = f.publisher.Publish(TopicFeeds, msgs...); err != nil {
-					log.Error().Err(err).Int("num", len(msgs)).Str("feed_id", feed.info.FeedID).Str("url", feed.info.FeedURL).Msg("could not publish feed messages")
+					info := feed.Info()
+					log.Error().Err(err).Int("num", len(msgs)).Str("feed_id", info.FeedID).Str("url", info.FeedURL).Msg("could not publish feed messages")
 					continue
 				}
+
+				// Items are only recorded as seen once they have been published
+				feed.markSeen(seen)
 			}
+		}()
+	}
+
+enqueue:
+	for _, feed := range interleaveHosts(feeds) {
+		select {
+		case queue <- feed:
+		case <-f.stop:
+			break enqueue
 		}
-	}()
-	return nil
+	}
+
+	close(queue)
+	wg.Wait()
 }
 
-func (f *FeedSync) Stop() {
-	close(f.stop)
+// Synchronize the feed and persist its updated fetch state to the manifest. No more
+// than the configured number of feeds from the same host are synchronized at a time.
+// The returned items must be recorded as seen once the messages have been published.
+func (f *FeedSync) sync(feed *Feed) (msgs []*message.Message, seen *seenItems, err error) {
+	release := f.hosts.Acquire(feed.Host())
+	defer release()
+
+	// The feed is saved even if the sync fails so that its health is persisted.
+	msgs, seen, err = feed.syncItems()
+
+	// If the feed has permanently moved or was discovered from a web page, notify other
+	// consumers of the new url.
+	if previous := feed.Moved(); previous != "" {
+		f.publishMoved(feed, previous)
+	}
+
+	// The messages should still be published if the manifest can't be updated since
+	// the worst case is that the feed is refetched after a restart.
+	if err := f.manifest.Save(feed); err != nil {
+		info := feed.Info()
+		log.Warn().Err(err).Str("feed_id", info.FeedID).Str("url", info.FeedURL).Msg("could not save feed to manifest")
+	}
+
+	if err != nil {
+		return nil, nil, err
+	}
+	return msgs, seen, nil
+}
+
+// Publishes a subscription update with the new url of a feed that has permanently moved.
+func (f *FeedSync) publishMoved(feed *Feed, previous string) {
+	info := feed.Info()
+	info.PreviousURL = previous
+
+	msg, err := events.Marshal(&info, watermill.NewULID())
+	if err != nil {
+		log.Error().Err(err).Str("feed_id", info.FeedID).Str("url", info.FeedURL).Msg("could not marshal subscription update")
+		return
+	}
+
+	if err = f.publisher.Publish(TopicSubscriptions, msg); err != nil {
+		log.Error().Err(err).Str("feed_id", info.FeedID).Str("url", info.FeedURL).Msg("could not publish subscription update")
+	}
 }
 
-type Manifest map[string]*Feed
+// Manifest maintains the feeds that are currently being synchronized, keyed by their
+// feed url. If the manifest is backed by a database, every change to a feed is also
+// persisted so that the manifest can be reloaded when the feed sync is restarted. The
+// database also maintains an index of the items seen in each feed so that only new or
+// changed items are published; items not seen within the retention window are pruned.
+type Manifest struct {
+	sync.RWMutex
+	feeds       map[string]*Feed
+	db          *store.Manifest
+	retention   time.Duration
+	maxFailures int
+	schedule    Schedule
+}
 
+// Feed maintains the fetch state, schedule and health of a subscription. A feed that
+// fails too many consecutive syncs is deactivated and polled on the inactive interval
+// of the schedule until a sync succeeds and the feed is reactivated.
 type Feed struct {
-	info    *events.Subscription
-	fetcher *fetch.FeedFetcher
+	sync.Mutex
+	info        *events.Subscription
+	fetcher     *fetch.FeedFetcher
+	manifest    *Manifest
+	previousURL string
+	active      bool
+	error       string
+	reason      string
+	failures    int
+	lastSuccess time.Time
+	syncedAt    time.Time
+	nextSync    time.Time
+	interval    time.Duration
 }
 
-// Add or update the feed to the manifest
-func (m Manifest) Add(info *events.Subscription) *Feed {
-	// Update the feed with the new info
-	if feed, ok := m[info.FeedURL]; ok {
-		if feed.info.FeedID == "" || (info.FeedID != "" && feed.info.FeedID != info.FeedID) {
-			feed.info.FeedID = info.FeedID
+// NewManifest creates a manifest that is persisted to the specified database. If the
+// database is nil then the manifest is only maintained in memory.
+func NewManifest(db *store.Manifest) *Manifest {
+	return &Manifest{
+		feeds: make(map[string]*Feed),
+		db:    db,
+	}
+}
+
+// Configure the item retention, polling schedule and failure threshold of the manifest.
+func (m *Manifest) Configure(conf config.FeedSyncConfig) {
+	m.Lock()
+	defer m.Unlock()
+	m.retention = conf.ItemRetention
+	m.maxFailures = conf.MaxFailures
+	m.schedule = NewSchedule(conf)
+}
+
+// Load the feeds persisted in the database into the manifest. Feeds that have already
+// been added to the manifest are not overwritten by their persisted state.
+func (m *Manifest) Load() (err error) {
+	if m.db == nil {
+		return nil
+	}
+
+	var records []*store.Feed
+	if records, err = m.db.Feeds(); err != nil {
+		return err
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	for _, record := range records {
+		if _, ok := m.feeds[record.URL]; ok {
+			continue
 		}
 
-		if feed.info.FeedType == "" || (info.FeedType != "" && feed.info.FeedType != info.FeedType) {
-			feed.info.FeedType = info.FeedType
+		feed := &Feed{
+			info: &events.Subscription{
+				FeedID:     record.FeedID,
+				Title:      record.Title,
+				FeedType:   record.FeedType,
+				FeedURL:    record.URL,
+				SiteURL:    record.SiteURL,
+				Categories: record.Categories,
+			},
+			fetcher:     fetch.NewFeedFetcher(record.URL),
+			manifest:    m,
+			active:      record.Active,
+			error:       record.Error,
+			reason:      record.Reason,
+			failures:    record.Failures,
+			lastSuccess: record.LastSuccess,
+			syncedAt:    record.SyncedAt,
+			nextSync:    record.NextSync,
+			interval:    record.Interval,
 		}
+		feed.fetcher.Restore(record.ETag, record.LastModified)
+		m.feeds[record.URL] = feed
+	}
+	return nil
+}
+
+// Add or update the feed to the manifest. The manifest is only locked to look up or
+// insert the feed; existing feeds are updated while holding only their own lock so that
+// a subscription to a feed that is being synchronized does not block the manifest.
+func (m *Manifest) Add(info *events.Subscription) (_ *Feed, err error) {
+	// A feed that has permanently moved is rekeyed to its new url unless the new url is
+	// already in the manifest; the conditional http state of the feed is preserved.
+	if info.PreviousURL != "" && info.PreviousURL != info.FeedURL {
+		m.RLock()
+		_, exists := m.feeds[info.FeedURL]
+		feed, ok := m.feeds[info.PreviousURL]
+		m.RUnlock()
 
-		if feed.info.SiteURL == "" || (info.SiteURL != "" && feed.info.SiteURL != info.SiteURL) {
-			feed.info.SiteURL = info.SiteURL
+		if ok && !exists {
+			feed.Lock()
+			feed.info.FeedURL = info.FeedURL
+			etag, modified := feed.fetcher.ETag(), feed.fetcher.Modified()
+			feed.fetcher = fetch.NewFeedFetcher(info.FeedURL)
+			feed.fetcher.Restore(etag, modified)
+			feed.Unlock()
+
+			if _, err = m.Move(feed, info.PreviousURL); err != nil {
+				return nil, err
+			}
 		}
+	}
 
-		return feed
+	m.Lock()
+	feed, ok := m.feeds[info.FeedURL]
+	if !ok {
+		feed, err = m.create(info)
+		m.Unlock()
+		return feed, err
 	}
+	m.Unlock()
 
-	// Create the Feed and return it
+	// Update the feed with the new info
+	feed.Lock()
+	if feed.info.FeedID == "" || (info.FeedID != "" && feed.info.FeedID != info.FeedID) {
+		feed.info.FeedID = info.FeedID
+	}
+
+	if feed.info.FeedType == "" || (info.FeedType != "" && feed.info.FeedType != info.FeedType) {
+		feed.info.FeedType = info.FeedType
+	}
+
+	if feed.info.SiteURL == "" || (info.SiteURL != "" && feed.info.SiteURL != info.SiteURL) {
+		feed.info.SiteURL = info.SiteURL
+	}
+
+	if len(info.Categories) > 0 {
+		feed.info.Categories = info.Categories
+	}
+
+	// Subscribing to a deactivated feed again gives it another chance to recover
+	if !feed.active {
+		feed.active = true
+		feed.reason = ""
+		feed.failures = 0
+	}
+	feed.Unlock()
+
+	if err = m.Save(feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// Create the feed and add it to the manifest; the manifest must be locked by the caller.
+func (m *Manifest) create(info *events.Subscription) (_ *Feed, err error) {
+	info.PreviousURL = ""
 	if info.FeedID == "" {
 		info.FeedID = watermill.NewShortUUID()
 	}
 
 	feed := &Feed{
-		info:    info,
-		fetcher: fetch.NewFeedFetcher(info.FeedURL),
+		info:     info,
+		fetcher:  fetch.NewFeedFetcher(info.FeedURL),
+		manifest: m,
+		active:   true,
+	}
+
+	if err = m.save(feed.record()); err != nil {
+		return nil, err
+	}
+
+	m.feeds[info.FeedURL] = feed
+	return feed, nil
+}
+
+// Save the current state of the feed to the manifest database. The state of the feed is
+// read before the manifest is locked since the feed is locked while it is synchronized.
+// Feeds that have been removed from the manifest, e.g. duplicates, are not saved.
+func (m *Manifest) Save(feed *Feed) error {
+	record := feed.record()
+	m.RLock()
+	defer m.RUnlock()
+
+	if m.feeds[record.URL] != feed {
+		return nil
+	}
+	return m.save(record)
+}
+
+// Moves the feed from its previous url to its current url in the manifest and removes
+// the record of the previous url from the database. If another feed is already
+// subscribed to the current url, the moved feed is a duplicate and is removed from the
+// manifest along with its seen items, keeping the existing feed; false is returned.
+func (m *Manifest) Move(feed *Feed, previous string) (moved bool, err error) {
+	record := feed.record()
+
+	m.Lock()
+	defer m.Unlock()
+	if m.feeds[previous] == feed {
+		delete(m.feeds, previous)
+	}
+
+	if m.db != nil {
+		if err = m.db.Delete(previous); err != nil {
+			return false, err
+		}
+	}
+
+	if existing, ok := m.feeds[record.URL]; ok && existing != feed {
+		return false, m.removeItems(record)
+	}
+
+	m.feeds[record.URL] = feed
+	return true, m.save(record)
+}
+
+// Removes the seen items of a duplicate feed unless they belong to the existing feed.
+// The existing feed may be synchronizing so its id is read from the database instead.
+func (m *Manifest) removeItems(duplicate *store.Feed) error {
+	if m.db == nil {
+		return nil
+	}
+
+	existing, err := m.db.Get(duplicate.URL)
+	if err == nil && existing.FeedID == duplicate.FeedID {
+		return nil
+	}
+	return m.db.DeleteItems(duplicate.FeedID)
+}
+
+func (m *Manifest) save(record *store.Feed) error {
+	if m.db == nil {
+		return nil
+	}
+	return m.db.Put(record)
+}
+
+// Feeds returns a snapshot of the feeds currently in the manifest.
+func (m *Manifest) Feeds() []*Feed {
+	m.RLock()
+	defer m.RUnlock()
+
+	feeds := make([]*Feed, 0, len(m.feeds))
+	for _, feed := range m.feeds {
+		feeds = append(feeds, feed)
+	}
+	return feeds
+}
+
+// Due returns the feeds that are scheduled to be synchronized at or before now.
+func (m *Manifest) Due(now time.Time) []*Feed {
+	feeds := m.Feeds()
+	due := make([]*Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if feed.Due(now) {
+			due = append(due, feed)
+		}
+	}
+	return due
+}
+
+// Len returns the number of feeds in the manifest.
+func (m *Manifest) Len() int {
+	m.RLock()
+	defer m.RUnlock()
+	return len(m.feeds)
+}
+
+// Close the manifest database if one is in use.
+func (m *Manifest) Close() error {
+	if m.db == nil {
+		return nil
 	}
-	m[info.FeedURL] = feed
-	return feed
+	return m.db.Close()
 }
 
-// Sync the feed and return the FeedItem events to publish
-func (f *Feed) Sync() (msgs []*message.Message, err error) {
+// Sync the feed and return the FeedItem events to publish. A feed is only synchronized
+// by one go routine at a time since the fetcher maintains the state of the last request.
+// If the feed has permanently moved, it is moved to its new url in the manifest. The
+// returned items are not recorded in the seen-item index until markSeen is called once
+// the events have been published, so that they are returned again by the next sync if
+// the events could not be published.
+func (f *Feed) syncItems() (msgs []*message.Message, seen *seenItems, err error) {
+	msgs, seen, err = f.sync()
+
+	// The manifest is locked before its feeds so the feed must be unlocked to move it.
+	if previous := f.Moved(); previous != "" && f.manifest != nil {
+		info := f.Info()
+		log.Info().Str("feed_id", info.FeedID).Str("url", info.FeedURL).Str("previous_url", previous).Msg("feed has permanently moved")
+
+		moved, merr := f.manifest.Move(f, previous)
+		if merr != nil {
+			log.Warn().Err(merr).Str("feed_id", info.FeedID).Str("url", info.FeedURL).Msg("could not move feed in manifest")
+		}
+
+		// If the feed moved to a url that is already subscribed, the duplicate has been
+		// removed and its items are not published since the existing feed publishes them.
+		if merr == nil && !moved {
+			log.Info().Str("feed_id", info.FeedID).Str("url", info.FeedURL).Msg("removed duplicate feed")
+			f.Lock()
+			f.previousURL = ""
+			f.Unlock()
+			return nil, nil, err
+		}
+	}
+	return msgs, seen, err
+}
+
+func (f *Feed) sync() (msgs []*message.Message, seen *seenItems, err error) {
+	f.Lock()
+	defer f.Unlock()
+
 	log.Info().Str("feed_id", f.info.FeedID).Str("url", f.info.FeedURL).Msg("synchronizing feed")
 	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
 	defer cancel()
 
 	var rss *gofeed.Feed
-	if rss, err = f.fetcher.Fetch(ctx); err != nil {
-		if httperr, ok := err.(*fetch.HTTPError); ok {
-			// If it is an http error emit an fsync event
-			fsync := &events.FeedSync{
-				FeedID:     f.info.FeedID,
-				Active:     false,
-				Error:      httperr.Status,
-				StatusCode: httperr.Code,
-				SyncedAt:   time.Now(),
-				Title:      f.info.Title,
-				Link:       f.info.FeedURL,
-				FeedType:   f.info.FeedType,
+	start := time.Now()
+	previous := f.info.FeedURL
+	rss, err = f.fetcher.Fetch(ctx)
+
+	// If the url is a web page (e.g. the url of the site rather than of its feed) then
+	// subscribe to the feed discovered from the page instead if it can be fetched. Sites
+	// often link to the same feed in several formats so only the first feed is used.
+	var notFeed fetch.NotFeedError
+	if errors.As(err, &notFeed) && len(notFeed.Feeds) > 0 {
+		log.Info().Str("feed_id", f.info.FeedID).Str("url", f.info.FeedURL).Strs("feeds", notFeed.Feeds).Msg("discovered feeds from web page")
+		fetcher := fetch.NewFeedFetcher(notFeed.Feeds[0])
+		if rss, err = fetcher.Fetch(ctx); err == nil {
+			f.fetcher = fetcher
+			if f.info.SiteURL == "" {
+				f.info.SiteURL = previous
 			}
+		}
+	}
+	metrics.FetchLatency.WithLabelValues(metrics.NodeID(), HandlerFeedSync).Observe(time.Since(start).Seconds())
 
-			var msg *message.Message
-			if msg, err = events.Marshal(fsync, watermill.NewULID()); err != nil {
-				return nil, err
+	// Permanent redirects and discovered feeds change the url of the feed; temporary
+	// redirects are ignored.
+	f.previousURL = ""
+	if u := f.fetcher.URL(); u != previous {
+		f.info.FeedURL = u
+		f.previousURL = previous
+	}
+
+	if err != nil {
+		// If the feed is disallowed by robots.txt emit an fsync event with the reason
+		if errors.Is(err, fetch.ErrDisallowed) {
+			metrics.FeedSyncs.WithLabelValues(metrics.NodeID(), statusDisallowed).Inc()
+			return withoutItems(f.failed(&events.FeedSync{Error: err.Error(), Disallowed: true}))
+		}
+
+		// If the url is a web page without any feeds emit an fsync event with the reason
+		if errors.As(err, &notFeed) {
+			metrics.FeedSyncs.WithLabelValues(metrics.NodeID(), statusNotFeed).Inc()
+			return withoutItems(f.failed(&events.FeedSync{Error: err.Error()}))
+		}
+
+		var httperr fetch.HTTPError
+		if !errors.As(err, &httperr) {
+			// Other errors are returned rather than published unless the failure
+			// deactivates the feed, in which case the deactivation is published.
+			metrics.FeedSyncs.WithLabelValues(metrics.NodeID(), statusError).Inc()
+			fsync := &events.FeedSync{Error: err.Error()}
+			if f.fail(fsync) {
+				return withoutItems(marshalFeedSync(fsync))
 			}
+			return nil, nil, err
+		}
+
+		metrics.FeedSyncs.WithLabelValues(metrics.NodeID(), strconv.Itoa(httperr.Code)).Inc()
 
-			return []*message.Message{msg}, nil
+		// A 304 means the feed hasn't changed since the last sync, which is a success.
+		if httperr.NotModified() {
+			return withoutItems(f.notModified())
 		}
 
-		return nil, err
+		// If it is an http error emit an fsync event
+		return withoutItems(f.failed(&events.FeedSync{Error: httperr.Status, StatusCode: httperr.Code}))
 	}
 
+	metrics.FetchSize.WithLabelValues(metrics.NodeID(), HandlerFeedSync).Observe(float64(f.fetcher.Size()))
+	metrics.FeedSyncs.WithLabelValues(metrics.NodeID(), strconv.Itoa(http.StatusOK)).Inc()
 	msgs = make([]*message.Message, 0, len(rss.Items)+1)
 
 	fsync := &events.FeedSync{
@@ -222,24 +719,52 @@ func (f *Feed) Sync() (msgs []*message.Message, err error) {
 		FeedVersion:  rss.FeedVersion,
 	}
 
+	f.succeeded(fsync.SyncedAt)
+	f.reschedule(f.schedule().Interval(rss, f.fetcher.Expires(), fsync.SyncedAt), fsync.SyncedAt)
+
 	var msg *message.Message
 	if msg, err = events.Marshal(fsync, watermill.NewULID()); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	msgs = append(msgs, msg)
 
-	// Handle each feed item
+	// Handle each feed item that has not already been published; the items are recorded
+	// in the seen-item index by the caller once the events have been published.
+	seen = &seenItems{feedID: f.info.FeedID, syncedAt: fsync.SyncedAt}
 	for _, item := range rss.Items {
+		isNew, record := f.isNew(item, fsync.SyncedAt)
+		if record != nil {
+			seen.items = append(seen.items, record)
+		}
+
+		if !isNew {
+			continue
+		}
+
 		fitem := &events.FeedItem{
 			FeedID:      f.info.FeedID,
 			Title:       item.Title,
 			Description: item.Description,
 			Content:     item.Content,
+			ContentText: fetch.ContentText(item),
 			Link:        item.Link,
 			Updated:     item.Updated,
 			Published:   item.Published,
 			GUID:        item.GUID,
 			Categories:  item.Categories,
+			Language:    rss.Language,
+		}
+
+		// JSON Feed items may specify a language that differs from that of the feed
+		if lang := fetch.ItemLanguage(item); lang != "" {
+			fitem.Language = lang
+		}
+
+		switch {
+		case item.PublishedParsed != nil:
+			fitem.PublishedAt = *item.PublishedParsed
+		case item.UpdatedParsed != nil:
+			fitem.PublishedAt = *item.UpdatedParsed
 		}
 
 		if item.Image != nil {
@@ -270,10 +795,309 @@ func (f *Feed) Sync() (msgs []*message.Message, err error) {
 
 		var msg *message.Message
 		if msg, err = events.Marshal(fitem, watermill.NewULID()); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		msgs = append(msgs, msg)
 	}
 
-	return msgs, nil
+	// The first message is the feed sync, the remainder are the new feed items
+	metrics.FeedItems.WithLabelValues(metrics.NodeID(), f.info.FeedID).Add(float64(len(msgs) - 1))
+
+	return msgs, seen, nil
+}
+
+// Returns an active FeedSync event with no items when the feed has not been modified
+// since the last sync. The feed is rescheduled on its previous interval unless the
+// response's cache headers request a longer interval.
+func (f *Feed) notModified() (_ []*message.Message, err error) {
+	fsync := &events.FeedSync{
+		FeedID:       f.info.FeedID,
+		ETag:         f.fetcher.ETag(),
+		LastModified: f.fetcher.Modified(),
+		Active:       true,
+		NotModified:  true,
+		StatusCode:   http.StatusNotModified,
+		SyncedAt:     time.Now(),
+		Title:        f.info.Title,
+		Link:         f.info.FeedURL,
+		FeedType:     f.info.FeedType,
+	}
+
+	// A deactivated feed is not polled on its previous (inactive) interval once reactivated
+	reactivated := !f.active
+	f.succeeded(fsync.SyncedAt)
+
+	schedule := f.schedule()
+	interval := f.interval
+	if interval == 0 || reactivated {
+		interval = schedule.Default
+	}
+
+	if cache := f.fetcher.Expires().Sub(fsync.SyncedAt); cache > interval {
+		interval = cache
+	}
+	f.reschedule(schedule.Bound(interval), fsync.SyncedAt)
+
+	metrics.FeedSyncsUnmodified.WithLabelValues(metrics.NodeID()).Inc()
+	return marshalFeedSync(fsync)
+}
+
+// Returns an inactive FeedSync event when the feed could not be fetched, e.g. because
+// of an http error or because it is disallowed by robots.txt, with the reason for the
+// failure specified by the caller.
+func (f *Feed) failed(fsync *events.FeedSync) (_ []*message.Message, err error) {
+	f.fail(fsync)
+	return marshalFeedSync(fsync)
+}
+
+// Records a failed sync of the feed and populates the FeedSync event. The feed is
+// deactivated if it is gone (410) or has failed the maximum number of consecutive
+// syncs; deactivated feeds are polled on the inactive interval and other failing feeds
+// are polled on the default interval. Returns true if this failure deactivated the feed.
+func (f *Feed) fail(fsync *events.FeedSync) (deactivated bool) {
+	fsync.FeedID = f.info.FeedID
+	fsync.Active = false
+	fsync.SyncedAt = time.Now()
+	fsync.Title = f.info.Title
+	fsync.Link = f.info.FeedURL
+	fsync.FeedType = f.info.FeedType
+
+	f.failures++
+	f.error = fsync.Error
+	f.syncedAt = fsync.SyncedAt
+
+	if f.active {
+		var maxFailures int
+		if f.manifest != nil {
+			maxFailures = f.manifest.maxFailures
+		}
+
+		switch {
+		case fsync.StatusCode == http.StatusGone:
+			f.reason = fmt.Sprintf("feed is gone: %s", fsync.Error)
+		case maxFailures > 0 && f.failures >= maxFailures:
+			f.reason = fmt.Sprintf("feed failed %d consecutive syncs: %s", f.failures, fsync.Error)
+		}
+
+		if f.reason != "" {
+			f.active = false
+			deactivated = true
+			metrics.FeedsDeactivated.WithLabelValues(metrics.NodeID()).Inc()
+			log.Warn().Str("feed_id", f.info.FeedID).Str("url", f.info.FeedURL).Str("reason", f.reason).Msg("feed deactivated")
+		}
+	}
+
+	fsync.Failures = f.failures
+	fsync.Deactivated = !f.active
+	fsync.Reason = f.reason
+
+	schedule := f.schedule()
+	if !f.active && schedule.Inactive > 0 {
+		f.reschedule(schedule.Inactive, fsync.SyncedAt)
+	} else {
+		f.reschedule(schedule.Bound(schedule.Default), fsync.SyncedAt)
+	}
+	return deactivated
+}
+
+// Records a successful sync of the feed, reactivating it if it had been deactivated.
+func (f *Feed) succeeded(syncedAt time.Time) {
+	if !f.active {
+		log.Info().Str("feed_id", f.info.FeedID).Str("url", f.info.FeedURL).Msg("feed reactivated")
+	}
+
+	f.active = true
+	f.error = ""
+	f.reason = ""
+	f.failures = 0
+	f.lastSuccess = syncedAt
+	f.syncedAt = syncedAt
+}
+
+// Active returns false if the feed has been deactivated because it is failing.
+func (f *Feed) Active() bool {
+	f.Lock()
+	defer f.Unlock()
+	return f.active
+}
+
+// Health returns the number of consecutive failed syncs of the feed, when it was last
+// synchronized successfully, and the error of the last failed sync.
+func (f *Feed) Health() (failures int, lastSuccess time.Time, lastError string) {
+	f.Lock()
+	defer f.Unlock()
+	return f.failures, f.lastSuccess, f.error
+}
+
+// Info returns a copy of the subscription info of the feed.
+func (f *Feed) Info() events.Subscription {
+	f.Lock()
+	defer f.Unlock()
+	return *f.info
+}
+
+// URL returns the current url of the feed.
+func (f *Feed) URL() string {
+	f.Lock()
+	defer f.Unlock()
+	return f.info.FeedURL
+}
+
+// Moved returns the url the feed was subscribed to before it was permanently redirected
+// to its current url or discovered from the web page at its previous url during the
+// last sync, or an empty string if it did not move.
+func (f *Feed) Moved() string {
+	f.Lock()
+	defer f.Unlock()
+	return f.previousURL
+}
+
+// Due returns true if the feed is scheduled to be synchronized at or before now. A feed
+// that is currently being synchronized is not due.
+func (f *Feed) Due(now time.Time) bool {
+	if !f.TryLock() {
+		return false
+	}
+	defer f.Unlock()
+	return !f.nextSync.After(now)
+}
+
+// NextSync returns the time the feed is next scheduled to be synchronized.
+func (f *Feed) NextSync() time.Time {
+	f.Lock()
+	defer f.Unlock()
+	return f.nextSync
+}
+
+func (f *Feed) reschedule(interval time.Duration, now time.Time) {
+	f.interval = interval
+	f.nextSync = now.Add(interval)
+}
+
+func (f *Feed) schedule() Schedule {
+	if f.manifest == nil {
+		return Schedule{}
+	}
+	return f.manifest.schedule
+}
+
+// The items of a sync to record in the seen-item index once they have been published.
+type seenItems struct {
+	feedID   string
+	syncedAt time.Time
+	items    []*store.Item
+}
+
+// Checks the seen-item index to determine if the item is new or has changed since it
+// was last published and returns the updated index record to save once the item has
+// been published. If there is no index, all items are new and no record is returned.
+func (f *Feed) isNew(item *gofeed.Item, seenAt time.Time) (_ bool, seen *store.Item) {
+	if f.manifest == nil || f.manifest.db == nil {
+		return true, nil
+	}
+
+	key, hash := itemKey(item), itemHash(item)
+	seen, err := f.manifest.db.GetItem(f.info.FeedID, key)
+	switch {
+	case err == nil:
+		seen.LastSeen = seenAt
+	case errors.Is(err, store.ErrNotFound):
+		seen = &store.Item{Key: key, FirstSeen: seenAt, LastSeen: seenAt}
+	default:
+		log.Warn().Err(err).Str("feed_id", f.info.FeedID).Str("key", key).Msg("could not check seen item index")
+		return true, nil
+	}
+
+	isNew := seen.Hash != hash
+	seen.Hash = hash
+	return isNew, seen
+}
+
+// Records the items of a sync in the seen-item index once they have been published and
+// removes items that haven't been in the feed for longer than the retention window so
+// that the index does not grow without bound.
+func (f *Feed) markSeen(seen *seenItems) {
+	if seen == nil || f.manifest == nil || f.manifest.db == nil {
+		return
+	}
+
+	for _, item := range seen.items {
+		if err := f.manifest.db.PutItem(seen.feedID, item); err != nil {
+			log.Warn().Err(err).Str("feed_id", seen.feedID).Str("key", item.Key).Msg("could not update seen item index")
+		}
+	}
+
+	if f.manifest.retention <= 0 {
+		return
+	}
+
+	if _, err := f.manifest.db.PruneItems(seen.feedID, seen.syncedAt.Add(-f.manifest.retention)); err != nil {
+		log.Warn().Err(err).Str("feed_id", seen.feedID).Msg("could not prune seen item index")
+	}
+}
+
+// The seen-item index is keyed by the GUID of the item, falling back to the link and
+// published date of the item if the feed does not specify GUIDs.
+func itemKey(item *gofeed.Item) string {
+	if item.GUID != "" {
+		return item.GUID
+	}
+	return item.Link + " " + item.Published
+}
+
+// Hashes the content of the item to detect changes to items that have been seen.
+func itemHash(item *gofeed.Item) string {
+	hasher := murmur3.New64()
+	for _, field := range []string{item.Title, item.Description, item.Content, item.Link, item.Updated} {
+		hasher.Write([]byte(field))
+		hasher.Write([]byte{0})
+	}
+	return strconv.FormatUint(hasher.Sum64(), 16)
+}
+
+// Host returns the host of the feed url, used to limit concurrent requests per host.
+func (f *Feed) Host() string {
+	feedURL := f.URL()
+	if u, err := url.Parse(feedURL); err == nil {
+		return u.Hostname()
+	}
+	return feedURL
+}
+
+// Returns the persisted representation of the feed for the manifest database.
+func (f *Feed) record() *store.Feed {
+	f.Lock()
+	defer f.Unlock()
+
+	return &store.Feed{
+		URL:          f.info.FeedURL,
+		Active:       f.active,
+		Error:        f.error,
+		Reason:       f.reason,
+		Failures:     f.failures,
+		LastSuccess:  f.lastSuccess,
+		FeedID:       f.info.FeedID,
+		Title:        f.info.Title,
+		FeedType:     f.info.FeedType,
+		SiteURL:      f.info.SiteURL,
+		Categories:   f.info.Categories,
+		ETag:         f.fetcher.ETag(),
+		LastModified: f.fetcher.Modified(),
+		SyncedAt:     f.syncedAt,
+		NextSync:     f.nextSync,
+		Interval:     f.interval,
+	}
+}
+
+// Returns the FeedSync event of a sync that did not return any items to mark as seen.
+func withoutItems(msgs []*message.Message, err error) ([]*message.Message, *seenItems, error) {
+	return msgs, nil, err
+}
+
+func marshalFeedSync(fsync *events.FeedSync) (_ []*message.Message, err error) {
+	var msg *message.Message
+	if msg, err = events.Marshal(fsync, watermill.NewULID()); err != nil {
+		return nil, err
+	}
+	return []*message.Message{msg}, nil
 }
@@ -0,0 +1,715 @@
+package baleen_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rotationalio/baleen"
+	"github.com/rotationalio/baleen/config"
+	"github.com/rotationalio/baleen/events"
+	"github.com/rotationalio/baleen/fetch"
+	"github.com/rotationalio/baleen/metrics"
+	"github.com/rotationalio/baleen/store"
+	"github.com/rotationalio/watermill-ensign/pkg/ensign"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest")
+	manifest := baleen.NewManifest(store.OpenManifest(path))
+
+	_, err := manifest.Add(&events.Subscription{FeedURL: "https://example.com/rss", Title: "Example", Categories: []string{"News"}})
+	require.NoError(t, err, "could not add feed to manifest")
+	_, err = manifest.Add(&events.Subscription{FeedURL: "https://example.com/atom", FeedType: "atom"})
+	require.NoError(t, err, "could not add feed to manifest")
+
+	// Adding an existing feed should update rather than duplicate it
+	_, err = manifest.Add(&events.Subscription{FeedURL: "https://example.com/rss", SiteURL: "https://example.com"})
+	require.NoError(t, err, "could not update feed in manifest")
+	require.Equal(t, 2, manifest.Len())
+	require.NoError(t, manifest.Close())
+
+	// Reopen the manifest and ensure the feeds are reloaded
+	db := store.OpenManifest(path)
+	manifest = baleen.NewManifest(db)
+	defer manifest.Close()
+	require.Equal(t, 0, manifest.Len())
+	require.NoError(t, manifest.Load(), "could not load manifest")
+	require.Equal(t, 2, manifest.Len())
+
+	record, err := db.Get("https://example.com/rss")
+	require.NoError(t, err)
+	require.Equal(t, "Example", record.Title)
+	require.Equal(t, "https://example.com", record.SiteURL)
+	require.Equal(t, []string{"News"}, record.Categories, "categories should not be removed by an update without categories")
+	require.NotEmpty(t, record.FeedID)
+	require.True(t, record.Active)
+}
+
+func TestManifestAddDuringSync(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	url := feedServer(t, func() string {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		return rssFeed(rssItem("1", "First post"))
+	})
+
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	t.Cleanup(unblock)
+
+	manifest := baleen.NewManifest(store.OpenManifest(filepath.Join(t.TempDir(), "manifest")))
+	defer manifest.Close()
+
+	feed, err := manifest.Add(&events.Subscription{FeedURL: url})
+	require.NoError(t, err)
+
+	synced := make(chan error, 1)
+	go func() {
+		_, err := baleen.SyncFeed(feed, false)
+		synced <- err
+	}()
+	<-started
+
+	// Updating a feed that is being synchronized waits for the sync to complete
+	added := make(chan error, 1)
+	go func() {
+		_, err := manifest.Add(&events.Subscription{FeedURL: url, Categories: []string{"News"}})
+		added <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// But the rest of the manifest should not be blocked while the update is waiting
+	done := make(chan error, 1)
+	go func() {
+		manifest.Due(time.Now())
+		_, err := manifest.Add(&events.Subscription{FeedURL: url + "/other"})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+		require.Equal(t, 2, manifest.Len())
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "manifest is blocked by a feed that is being synchronized")
+	}
+
+	unblock()
+	require.NoError(t, <-synced)
+	require.NoError(t, <-added)
+	require.Equal(t, []string{"News"}, feed.Info().Categories)
+}
+
+func TestFeedItemDeduplication(t *testing.T) {
+	items := []string{rssItem("1", "First post"), rssItem("2", "Second post")}
+	url := feedServer(t, func() string { return rssFeed(items...) })
+
+	manifest := baleen.NewManifest(store.OpenManifest(filepath.Join(t.TempDir(), "manifest")))
+	defer manifest.Close()
+
+	feed, err := manifest.Add(&events.Subscription{FeedURL: url})
+	require.NoError(t, err)
+
+	// The first sync should publish the feed sync and all items
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem, events.TypeFeedItem}, messageTypes(msgs))
+
+	// If the items could not be published they should be returned by the next sync
+	msgs, err = baleen.SyncFeed(feed, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem, events.TypeFeedItem}, messageTypes(msgs))
+
+	// The next sync should only publish the feed sync since nothing has changed
+	msgs, err = baleen.SyncFeed(feed, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync}, messageTypes(msgs))
+
+	// New and changed items should be published but not unchanged items
+	items = []string{rssItem("1", "First post"), rssItem("2", "Second post (updated)"), rssItem("3", "Third post")}
+	msgs, err = baleen.SyncFeed(feed, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem, events.TypeFeedItem}, messageTypes(msgs))
+
+	item, err := events.UnmarshalFeedItem(msgs[1])
+	require.NoError(t, err)
+	require.Equal(t, "Second post (updated)", item.Title)
+}
+
+// Helper to serve an RSS feed whose content may change between requests.
+func feedServer(t *testing.T, feed func() string) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if feed == nil || r.URL.Path == "/404" {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, feed())
+	}))
+	t.Cleanup(server.Close)
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+	return server.URL
+}
+
+func rssFeed(items ...string) string {
+	return `<?xml version="1.0" encoding="utf-8"?><rss version="2.0"><channel><title>Sample Feed</title><link>http://example.org/</link>` + strings.Join(items, "") + `</channel></rss>`
+}
+
+func rssItem(guid, title string) string {
+	return fmt.Sprintf(`<item><title>%s</title><link>http://example.org/item/%s</link><guid>%s</guid></item>`, title, guid, guid)
+}
+
+func messageTypes(msgs []*message.Message) []string {
+	types := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		types = append(types, msg.Metadata.Get(ensign.TypeNameKey))
+	}
+	return types
+}
+
+func TestFeedDisallowed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: Baleen\nDisallow: /rss\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, rssFeed(rssItem("1", "First")))
+	}))
+	t.Cleanup(server.Close)
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+
+	manifest := baleen.NewManifest(nil)
+	feed, err := manifest.Add(&events.Subscription{FeedURL: server.URL + "/rss"})
+	require.NoError(t, err)
+
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err, "disallowed feeds should be published as feed sync events")
+	require.Equal(t, []string{events.TypeFeedSync}, messageTypes(msgs))
+
+	fsync, err := events.UnmarshalFeedSync(msgs[0])
+	require.NoError(t, err)
+	require.False(t, fsync.Active)
+	require.True(t, fsync.Disallowed)
+	require.Zero(t, fsync.StatusCode)
+	require.Contains(t, fsync.Error, "robots.txt")
+}
+
+func TestFeedNotModified(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == "ABCDEFG" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", "ABCDEFG")
+		fmt.Fprint(w, rssFeed(rssItem("1", "First post")))
+	}))
+	defer server.Close()
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+
+	manifest := baleen.NewManifest(nil)
+	feed, err := manifest.Add(&events.Subscription{FeedURL: server.URL})
+	require.NoError(t, err)
+
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+
+	// A 304 should be an active sync with no items
+	msgs, err = baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync}, messageTypes(msgs))
+
+	fsync, err := events.UnmarshalFeedSync(msgs[0])
+	require.NoError(t, err)
+	require.True(t, fsync.Active)
+	require.True(t, fsync.NotModified)
+	require.Equal(t, http.StatusNotModified, fsync.StatusCode)
+	require.Empty(t, fsync.Error)
+	require.Zero(t, fsync.FeedItems)
+	require.Equal(t, "ABCDEFG", fsync.ETag)
+}
+
+func TestFeedHTTPError(t *testing.T) {
+	url := feedServer(t, nil)
+
+	manifest := baleen.NewManifest(nil)
+	feed, err := manifest.Add(&events.Subscription{FeedURL: url})
+	require.NoError(t, err)
+
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err, "http errors should be published as feed sync events")
+	require.Equal(t, []string{events.TypeFeedSync}, messageTypes(msgs))
+
+	fsync, err := events.UnmarshalFeedSync(msgs[0])
+	require.NoError(t, err)
+	require.False(t, fsync.Active)
+	require.False(t, fsync.NotModified)
+	require.Equal(t, http.StatusNotFound, fsync.StatusCode)
+	require.NotEmpty(t, fsync.Error)
+}
+
+func TestFeedHealth(t *testing.T) {
+	var status int32 = http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch code := int(atomic.LoadInt32(&status)); {
+		case r.URL.Path == "/robots.txt":
+			http.NotFound(w, r)
+		case code == http.StatusOK:
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, rssFeed(rssItem("1", "First")))
+		case code == 0:
+			fmt.Fprint(w, "not a feed")
+		default:
+			w.WriteHeader(code)
+		}
+	}))
+	t.Cleanup(server.Close)
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+
+	path := filepath.Join(t.TempDir(), "manifest")
+	db := store.OpenManifest(path)
+	manifest := baleen.NewManifest(db)
+	manifest.Configure(config.FeedSyncConfig{Interval: time.Hour, MaxFailures: 3, InactiveInterval: 168 * time.Hour})
+	defer manifest.Close()
+
+	feed, err := manifest.Add(&events.Subscription{FeedURL: server.URL + "/rss"})
+	require.NoError(t, err)
+
+	sync := func() *events.FeedSync {
+		msgs, err := baleen.SyncFeed(feed, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{events.TypeFeedSync}, messageTypes(msgs))
+		require.NoError(t, manifest.Save(feed))
+
+		fsync, err := events.UnmarshalFeedSync(msgs[0])
+		require.NoError(t, err)
+		return fsync
+	}
+
+	// The feed remains active until it fails the maximum number of consecutive syncs
+	for i := 1; i < 3; i++ {
+		fsync := sync()
+		require.False(t, fsync.Active)
+		require.False(t, fsync.Deactivated)
+		require.Equal(t, i, fsync.Failures)
+		require.True(t, feed.Active())
+		require.WithinDuration(t, time.Now().Add(time.Hour), feed.NextSync(), time.Minute)
+	}
+
+	fsync := sync()
+	require.True(t, fsync.Deactivated)
+	require.Equal(t, 3, fsync.Failures)
+	require.Equal(t, "feed failed 3 consecutive syncs: 503 Service Unavailable", fsync.Reason)
+	require.False(t, feed.Active())
+	require.WithinDuration(t, time.Now().Add(168*time.Hour), feed.NextSync(), time.Minute)
+
+	record, err := db.Get(server.URL + "/rss")
+	require.NoError(t, err)
+	require.False(t, record.Active)
+	require.Equal(t, 3, record.Failures)
+	require.Equal(t, fsync.Reason, record.Reason)
+
+	// A successful sync reactivates the feed
+	atomic.StoreInt32(&status, http.StatusOK)
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	fsync, err = events.UnmarshalFeedSync(msgs[0])
+	require.NoError(t, err)
+	require.True(t, fsync.Active)
+	require.False(t, fsync.Deactivated)
+	require.True(t, feed.Active())
+
+	failures, lastSuccess, lastError := feed.Health()
+	require.Zero(t, failures)
+	require.WithinDuration(t, time.Now(), lastSuccess, time.Minute)
+	require.Empty(t, lastError)
+
+	// A feed that is gone is deactivated immediately
+	atomic.StoreInt32(&status, http.StatusGone)
+	fsync = sync()
+	require.True(t, fsync.Deactivated)
+	require.Equal(t, 1, fsync.Failures)
+	require.Equal(t, "feed is gone: 410 Gone", fsync.Reason)
+	require.False(t, feed.Active())
+
+	// Subscribing to the feed again reactivates it
+	_, err = manifest.Add(&events.Subscription{FeedURL: server.URL + "/rss"})
+	require.NoError(t, err)
+	require.True(t, feed.Active())
+
+	// Errors without a response are returned unless they deactivate the feed
+	atomic.StoreInt32(&status, 0)
+	for i := 1; i < 3; i++ {
+		_, err = baleen.SyncFeed(feed, false)
+		require.Error(t, err)
+	}
+
+	fsync = sync()
+	require.True(t, fsync.Deactivated)
+	require.Zero(t, fsync.StatusCode)
+	require.Contains(t, fsync.Reason, "feed failed 3 consecutive syncs")
+}
+
+func TestFeedSyncMetrics(t *testing.T) {
+	url := feedServer(t, func() string { return rssFeed(rssItem("1", "First post"), rssItem("2", "Second post")) })
+
+	manifest := baleen.NewManifest(nil)
+	feed, err := manifest.Add(&events.Subscription{FeedID: "metrics", FeedURL: url})
+	require.NoError(t, err)
+
+	syncs := metrics.FeedSyncs.WithLabelValues(metrics.NodeID(), "200")
+	items := metrics.FeedItems.WithLabelValues(metrics.NodeID(), "metrics")
+	latency := metrics.FetchLatency.WithLabelValues(metrics.NodeID(), baleen.HandlerFeedSync)
+	size := metrics.FetchSize.WithLabelValues(metrics.NodeID(), baleen.HandlerFeedSync)
+	nsyncs, nitems := testutil.ToFloat64(syncs), testutil.ToFloat64(items)
+	nlatency, nsize := sampleCount(t, latency), sampleCount(t, size)
+
+	_, err = baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Equal(t, nsyncs+1, testutil.ToFloat64(syncs))
+	require.Equal(t, nitems+2, testutil.ToFloat64(items))
+	require.Equal(t, nlatency+1, sampleCount(t, latency), "expected fetch latency to be observed")
+	require.Equal(t, nsize+1, sampleCount(t, size), "expected fetch size to be observed")
+}
+
+// Returns the number of observations made by a histogram.
+func sampleCount(t *testing.T, observer prometheus.Observer) uint64 {
+	metric := &dto.Metric{}
+	require.NoError(t, observer.(prometheus.Histogram).Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestFeedPermanentRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+		case "/temp":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/new":
+			fmt.Fprint(w, rssFeed(rssItem("1", "First post")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+
+	db := store.OpenManifest(filepath.Join(t.TempDir(), "manifest"))
+	manifest := baleen.NewManifest(db)
+	defer manifest.Close()
+
+	// A permanent redirect should move the feed to its new url in the manifest
+	feed, err := manifest.Add(&events.Subscription{FeedURL: server.URL + "/old", Title: "Moved"})
+	require.NoError(t, err)
+
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Len(t, msgs, 2)
+	require.Equal(t, server.URL+"/old", feed.Moved())
+	require.Equal(t, server.URL+"/new", feed.URL())
+	require.Equal(t, 1, manifest.Len())
+
+	_, err = db.Get(server.URL + "/old")
+	require.ErrorIs(t, err, store.ErrNotFound, "the old url should be removed from the manifest")
+	record, err := db.Get(server.URL + "/new")
+	require.NoError(t, err)
+	require.Equal(t, "Moved", record.Title)
+
+	// Subscribing to the new url should update the moved feed rather than add a feed
+	moved, err := manifest.Add(&events.Subscription{FeedURL: server.URL + "/new"})
+	require.NoError(t, err)
+	require.Same(t, feed, moved)
+
+	// The feed has not moved again since it is now fetched from the new url
+	_, err = baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Empty(t, feed.Moved())
+
+	// A temporary redirect should not change the url of the feed
+	feed, err = manifest.Add(&events.Subscription{FeedURL: server.URL + "/temp"})
+	require.NoError(t, err)
+
+	_, err = baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Empty(t, feed.Moved())
+	require.Equal(t, server.URL+"/temp", feed.URL())
+	require.Equal(t, 2, manifest.Len())
+
+	// A subscription update from another node should move the feed in the manifest
+	manifest = baleen.NewManifest(nil)
+	feed, err = manifest.Add(&events.Subscription{FeedURL: server.URL + "/old"})
+	require.NoError(t, err)
+
+	moved, err = manifest.Add(&events.Subscription{FeedURL: server.URL + "/new", PreviousURL: server.URL + "/old"})
+	require.NoError(t, err)
+	require.Same(t, feed, moved)
+	require.Equal(t, server.URL+"/new", feed.URL())
+	require.Equal(t, 1, manifest.Len())
+}
+
+func TestFeedSyncPublished(t *testing.T) {
+	url := feedServer(t, func() string { return rssFeed(rssItem("1", "First post"), rssItem("2", "Second post")) })
+
+	pubsub, err := baleen.CreatePublisher(config.PublisherConfig{GoChannel: config.GoChannelConfig{Enabled: true, BufferSize: 8}}, watermill.NopLogger{})
+	require.NoError(t, err)
+
+	fsync, err := baleen.NewFeedSync(config.FeedSyncConfig{Enabled: true, ManifestPath: filepath.Join(t.TempDir(), "manifest")}, pubsub)
+	require.NoError(t, err)
+	defer fsync.Stop()
+
+	handle := func() (*message.Message, []*message.Message) {
+		msg, err := events.Marshal(&events.Subscription{FeedID: "published", FeedURL: url}, watermill.NewULID())
+		require.NoError(t, err)
+
+		msgs, err := fsync.Handle(msg)
+		require.NoError(t, err)
+		return msg, msgs
+	}
+
+	// If the messages are not published the subscription is nacked and the items are
+	// returned again by the next sync.
+	msg, msgs := handle()
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem, events.TypeFeedItem}, messageTypes(msgs))
+	msg.Nack()
+
+	msg, msgs = handle()
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem, events.TypeFeedItem}, messageTypes(msgs))
+	msg.Ack()
+
+	// Once the messages are published the items are recorded as seen
+	require.Eventually(t, func() bool {
+		_, msgs = handle()
+		return len(msgs) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestFeedSyncMoved(t *testing.T) {
+	url := feedServer(t, func() string { return rssFeed(rssItem("1", "First post")) })
+	server := httptest.NewServer(http.RedirectHandler(url+"/rss", http.StatusPermanentRedirect))
+	t.Cleanup(server.Close)
+
+	pubsub, err := baleen.CreatePublisher(config.PublisherConfig{GoChannel: config.GoChannelConfig{Enabled: true, BufferSize: 8}}, watermill.NopLogger{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	C, err := pubsub.(message.Subscriber).Subscribe(ctx, baleen.TopicSubscriptions)
+	require.NoError(t, err)
+
+	fsync, err := baleen.NewFeedSync(config.FeedSyncConfig{Enabled: true, ManifestPath: filepath.Join(t.TempDir(), "manifest")}, pubsub)
+	require.NoError(t, err)
+	defer fsync.Stop()
+
+	msg, err := events.Marshal(&events.Subscription{FeedID: "moved", FeedURL: server.URL, Title: "Moved"}, watermill.NewULID())
+	require.NoError(t, err)
+
+	msgs, err := fsync.Handle(msg)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem}, messageTypes(msgs))
+
+	// A subscription update should be published with the new url of the feed
+	var update *message.Message
+	select {
+	case update = <-C:
+		update.Ack()
+	case <-ctx.Done():
+		require.FailNow(t, "no subscription update was published")
+	}
+
+	info, err := events.UnmarshalSubscription(update)
+	require.NoError(t, err)
+	require.Equal(t, "moved", info.FeedID)
+	require.Equal(t, "Moved", info.Title)
+	require.Equal(t, url+"/rss", info.FeedURL)
+	require.Equal(t, server.URL, info.PreviousURL)
+
+	// Handling the update should not synchronize the feed again
+	msgs, err = fsync.Handle(update)
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+}
+
+func TestFeedMovedToSubscribedURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><link rel="alternate" type="application/rss+xml" href="/feed"></head></html>`)
+		case "/feed":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, rssFeed(rssItem("1", "First post")))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+
+	db := store.OpenManifest(filepath.Join(t.TempDir(), "manifest"))
+	manifest := baleen.NewManifest(db)
+	defer manifest.Close()
+
+	feed, err := manifest.Add(&events.Subscription{FeedID: "feed", FeedURL: server.URL + "/feed"})
+	require.NoError(t, err)
+
+	msgs, err := baleen.SyncFeed(feed, true)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem}, messageTypes(msgs))
+
+	// The site links to the feed that is already subscribed so it is a duplicate
+	site, err := manifest.Add(&events.Subscription{FeedID: "site", FeedURL: server.URL + "/"})
+	require.NoError(t, err)
+	require.Equal(t, 2, manifest.Len())
+
+	msgs, err = baleen.SyncFeed(site, false)
+	require.NoError(t, err)
+	require.Empty(t, msgs, "items of the duplicate feed should not be published")
+	require.Empty(t, site.Moved())
+	require.NoError(t, manifest.Save(site))
+
+	// The existing feed, its id, and its seen items are kept
+	require.Equal(t, 1, manifest.Len())
+	require.Same(t, feed, manifest.Feeds()[0])
+	require.Equal(t, "feed", feed.Info().FeedID)
+
+	record, err := db.Get(server.URL + "/feed")
+	require.NoError(t, err)
+	require.Equal(t, "feed", record.FeedID)
+
+	_, err = db.Get(server.URL + "/")
+	require.ErrorIs(t, err, store.ErrNotFound)
+
+	msgs, err = baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync}, messageTypes(msgs), "seen items should not be published again")
+}
+
+func TestFeedDiscovery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><link rel="alternate" type="application/rss+xml" href="/feeds/site.xml"></head></html>`)
+		case "/feeds/site.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, rssFeed(rssItem("1", "First post")))
+		case "/empty/":
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><head><title>No feeds here</title></head></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	fetch.SetClient(server.Client())
+	fetch.SetRateLimit(fetch.RateLimit{})
+	fetch.SetRetryPolicy(fetch.RetryPolicy{})
+
+	manifest := baleen.NewManifest(nil)
+
+	// Subscribing to a site should subscribe to the feed discovered from the site
+	feed, err := manifest.Add(&events.Subscription{FeedURL: server.URL + "/"})
+	require.NoError(t, err)
+
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem}, messageTypes(msgs))
+	require.Equal(t, server.URL+"/feeds/site.xml", feed.URL())
+	require.Equal(t, server.URL+"/", feed.Moved())
+	require.Equal(t, server.URL+"/", feed.Info().SiteURL)
+	require.Equal(t, 1, manifest.Len())
+
+	// A site without feeds should be published as a failed sync
+	feed, err = manifest.Add(&events.Subscription{FeedURL: server.URL + "/empty/"})
+	require.NoError(t, err)
+
+	msgs, err = baleen.SyncFeed(feed, false)
+	require.NoError(t, err, "sites without feeds should be published as feed sync events")
+	require.Equal(t, []string{events.TypeFeedSync}, messageTypes(msgs))
+
+	fsync, err := events.UnmarshalFeedSync(msgs[0])
+	require.NoError(t, err)
+	require.False(t, fsync.Active)
+	require.Contains(t, fsync.Error, "no feeds were discovered")
+	require.Equal(t, server.URL+"/empty/", feed.URL())
+	require.Empty(t, feed.Moved())
+}
+
+func TestJSONFeedItems(t *testing.T) {
+	url := feedServer(t, func() string {
+		return `{
+			"version": "https://jsonfeed.org/version/1.1",
+			"title": "Sample JSON Feed",
+			"language": "en",
+			"authors": [{"name": "Jane Doe", "url": "https://example.org/jane"}],
+			"items": [{
+				"id": 1,
+				"url": "https://example.org/posts/1",
+				"title": "Bonjour",
+				"content_html": "<p>Bonjour le monde</p>",
+				"content_text": "Bonjour le monde",
+				"language": "fr",
+				"date_published": "2023-04-12T14:32:00Z",
+				"attachments": [{"url": "https://example.org/posts/1.mp3", "mime_type": "audio/mpeg"}]
+			}]
+		}`
+	})
+
+	manifest := baleen.NewManifest(nil)
+	feed, err := manifest.Add(&events.Subscription{FeedURL: url})
+	require.NoError(t, err)
+
+	msgs, err := baleen.SyncFeed(feed, false)
+	require.NoError(t, err)
+	require.Equal(t, []string{events.TypeFeedSync, events.TypeFeedItem}, messageTypes(msgs))
+
+	fsync, err := events.UnmarshalFeedSync(msgs[0])
+	require.NoError(t, err)
+	require.Equal(t, "json", fsync.FeedType)
+	require.Equal(t, "1.1", fsync.FeedVersion)
+
+	fitem, err := events.UnmarshalFeedItem(msgs[1])
+	require.NoError(t, err)
+	require.Equal(t, "1", fitem.GUID)
+	require.Equal(t, "<p>Bonjour le monde</p>", fitem.Content)
+	require.Equal(t, "Bonjour le monde", fitem.ContentText)
+	require.Equal(t, "fr", fitem.Language, "the item language should override the feed language")
+	require.Equal(t, []string{"Jane Doe"}, fitem.Authors)
+	require.Equal(t, []string{"https://example.org/posts/1.mp3"}, fitem.Enclosures)
+	require.Equal(t, time.Date(2023, 4, 12, 14, 32, 0, 0, time.UTC), fitem.PublishedAt.UTC())
+}